@@ -1,40 +1,648 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"weather-app-backend/utils"
 )
 
+// DefaultEnvPath est le fichier .env de base, relatif au dossier backend.
 const DefaultEnvPath = "./config/.env"
 
-func Load(envPath ...string) error {
-	if _, ok := os.LookupEnv("PORT"); ok {
-		log.Println("[config] PORT already set in environment, skipping .env load")
-		return nil
+// DefaultEnvFiles retourne les fichiers .env présents parmi le fichier de
+// base et sa surcouche d'environnement (./config/.env.<APP_ENV>) ; les
+// fichiers absents sont signalés à logger (qui peut être nil).
+func DefaultEnvFiles(logger *slog.Logger) []string {
+	logger = utils.OrDiscard(logger)
+	candidates := []string{DefaultEnvPath}
+	if appEnv := os.Getenv("APP_ENV"); appEnv != "" {
+		candidates = append(candidates, DefaultEnvPath+"."+appEnv)
 	}
 
-	path := DefaultEnvPath
-	if len(envPath) > 0 && envPath[0] != "" {
-		path = envPath[0]
+	var files []string
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			logger.Info("env file not found, skipping", "path", path)
+			continue
+		}
+		files = append(files, path)
 	}
+	return files
+}
+
+// Identifiants des fournisseurs connus.
+const (
+	ProviderWeatherAPI = "weatherapi"
+	ProviderOpenMeteo  = "openmeteo"
+)
+
+// Modes de combinaison des fournisseurs.
+const (
+	ModeChain    = "chain"    // premier fournisseur disponible, bascule en cas de panne
+	ModeEnsemble = "ensemble" // tous les fournisseurs en parallèle, résultats fusionnés
+)
+
+// Config est la configuration complète de l'application.
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	Providers ProvidersConfig `yaml:"providers" toml:"providers"`
+	Cache     CacheConfig     `yaml:"cache" toml:"cache"`
+	Alerts    AlertsConfig    `yaml:"alerts" toml:"alerts"`
+	Health    HealthConfig    `yaml:"health" toml:"health"`
+	Logging   LoggingConfig   `yaml:"logging" toml:"logging"`
+	Tracing   TracingConfig   `yaml:"tracing" toml:"tracing"`
+	Admin     AdminConfig     `yaml:"admin" toml:"admin"`
+}
+
+// ServerConfig concerne le serveur HTTP.
+type ServerConfig struct {
+	Port        int    `yaml:"port" toml:"port"`
+	FrontendDir string `yaml:"frontend_dir" toml:"frontend_dir"`
+
+	RequestTimeout time.Duration            `yaml:"request_timeout" toml:"request_timeout"` // échéance des requêtes /api (0 = aucune)
+	RouteTimeouts  map[string]time.Duration `yaml:"route_timeouts" toml:"route_timeouts"`   // échéances propres à une route
+
+	// Réglages de http.Server (0 = aucune limite), lus au démarrage
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"` // lecture des en-têtes (slowloris)
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout"`               // lecture de toute la requête
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout"`             // écriture de la réponse
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`               // connexion keep-alive inactive
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" toml:"max_header_bytes"`
+	// ShutdownTimeout borne l'arrêt (fin des requêtes en cours, des tâches
+	// de fond et vidage du cache) après SIGINT/SIGTERM.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+// Listener regroupe les réglages de server.* qui ne s'appliquent qu'au
+// démarrage (un rechargement qui les modifie demande un redémarrage).
+type Listener struct {
+	Port              int
+	FrontendDir       string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+}
+
+// Listener retourne les réglages lus au démarrage.
+func (c ServerConfig) Listener() Listener {
+	return Listener{
+		Port:              c.Port,
+		FrontendDir:       c.FrontendDir,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		ReadTimeout:       c.ReadTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}
+}
+
+// RouteTimeout retourne l'échéance de la route path.
+func (c ServerConfig) RouteTimeout(path string) time.Duration {
+	if d, ok := c.RouteTimeouts[path]; ok {
+		return d
+	}
+	return c.RequestTimeout
+}
+
+// RateLimitConfig concerne la limite de débit par client des routes /api.
+// Un client est identifié par sa clé (en-tête APIKeyHeader, si elle figure
+// dans ClientKeys) ou par son adresse IP ; X-Forwarded-For n'est lu que si
+// la connexion vient d'un proxy de TrustedProxies.
+type RateLimitConfig struct {
+	Enabled        bool                 `yaml:"enabled" toml:"enabled"`
+	RPS            float64              `yaml:"rps" toml:"rps"`                         // requêtes par seconde et par client (0 = illimité)
+	Burst          int                  `yaml:"burst" toml:"burst"`                     // requêtes autorisées d'affilée
+	Routes         map[string]RouteRate `yaml:"routes" toml:"routes"`                   // limites propres à une route, ex. /api/weather
+	TrustedProxies []string             `yaml:"trusted_proxies" toml:"trusted_proxies"` // IP ou CIDR
+	APIKeyHeader   string               `yaml:"api_key_header" toml:"api_key_header"`
+	ClientKeys     []string             `yaml:"client_keys" toml:"client_keys" secret:"true"`
+	IdleTimeout    time.Duration        `yaml:"idle_timeout" toml:"idle_timeout"` // oubli des clients inactifs
+}
+
+// RouteRate est la limite d'une route.
+type RouteRate struct {
+	RPS   float64 `yaml:"rps" toml:"rps"`
+	Burst int     `yaml:"burst" toml:"burst"`
+}
+
+// Route retourne la limite de la route path (limite générale par défaut).
+func (c RateLimitConfig) Route(path string) RouteRate {
+	if r, ok := c.Routes[path]; ok {
+		return r
+	}
+	return RouteRate{RPS: c.RPS, Burst: c.Burst}
+}
+
+// ProvidersConfig décrit les fournisseurs météo et leur combinaison.
+type ProvidersConfig struct {
+	Order           []string           `yaml:"order" toml:"order"` // priorité (chain) ou membres (ensemble)
+	Mode            string             `yaml:"mode" toml:"mode"`
+	Cooldown        time.Duration      `yaml:"cooldown" toml:"cooldown"`
+	EnsembleWeights map[string]float64 `yaml:"ensemble_weights" toml:"ensemble_weights"`
+	HTTPTimeout     time.Duration      `yaml:"http_timeout" toml:"http_timeout"`
+
+	WeatherAPI WeatherAPIConfig `yaml:"weatherapi" toml:"weatherapi"`
+	OpenMeteo  OpenMeteoConfig  `yaml:"openmeteo" toml:"openmeteo"`
+}
+
+// WeatherAPIConfig concerne WeatherAPI.com.
+type WeatherAPIConfig struct {
+	APIKey  string   `yaml:"api_key" toml:"api_key" secret:"true"`
+	APIKeys []string `yaml:"api_keys" toml:"api_keys" secret:"true"` // pool de clés, utilisées à tour de rôle
+	URL     string   `yaml:"url" toml:"url"`                         // endpoint forecast.json
+
+	KeyQuota    int    `yaml:"key_quota" toml:"key_quota"`       // appels max par clé et par période (0 = illimité)
+	QuotaPeriod string `yaml:"quota_period" toml:"quota_period"` // month, day ou hour
+
+	HTTP UpstreamConfig `yaml:"http" toml:"http"`
+}
+
+// Keys retourne les clés configurées (APIKey puis APIKeys), sans doublon.
+func (c WeatherAPIConfig) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, k := range append([]string{c.APIKey}, c.APIKeys...) {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Périodes de remise à zéro des quotas de clés.
+const (
+	QuotaMonth = "month"
+	QuotaDay   = "day"
+	QuotaHour  = "hour"
+)
+
+// OpenMeteoConfig concerne Open-Meteo (sans clé).
+type OpenMeteoConfig struct {
+	ForecastURL   string `yaml:"forecast_url" toml:"forecast_url"`
+	AirQualityURL string `yaml:"air_quality_url" toml:"air_quality_url"`
+	GeocodingURL  string `yaml:"geocoding_url" toml:"geocoding_url"`
+
+	HTTP UpstreamConfig `yaml:"http" toml:"http"`
+}
+
+// UpstreamConfig règle le client HTTP d'un fournisseur. Seules les requêtes
+// GET sont rejouées, sur erreur réseau, 429 ou 5xx, dans la limite de
+// providers.http_timeout. Le disjoncteur s'ouvre après BreakerThreshold
+// échecs consécutifs (erreur réseau ou 5xx, nouvelles tentatives épuisées).
+// Au-delà de RateLimit, les appels attendent leur tour tant que l'échéance
+// de la requête le permet.
+type UpstreamConfig struct {
+	MaxRetries     int           `yaml:"max_retries" toml:"max_retries"`           // nouvelles tentatives (0 = aucune)
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay" toml:"retry_base_delay"` // premier délai, doublé à chaque tentative (avec jitter)
+	RetryMaxDelay  time.Duration `yaml:"retry_max_delay" toml:"retry_max_delay"`   // plafond du délai, Retry-After compris
+
+	BreakerThreshold      int           `yaml:"breaker_threshold" toml:"breaker_threshold"`               // 0 = pas de disjoncteur
+	BreakerOpenTimeout    time.Duration `yaml:"breaker_open_timeout" toml:"breaker_open_timeout"`         // durée d'ouverture avant les appels d'essai
+	BreakerHalfOpenProbes int           `yaml:"breaker_half_open_probes" toml:"breaker_half_open_probes"` // appels d'essai simultanés
+
+	RateLimit float64 `yaml:"rate_limit" toml:"rate_limit"` // appels sortants par seconde, tentatives comprises (0 = illimité)
+	RateBurst int     `yaml:"rate_burst" toml:"rate_burst"` // appels autorisés d'affilée
+}
+
+// Upstream retourne le réglage HTTP du fournisseur name.
+func (c ProvidersConfig) Upstream(name string) UpstreamConfig {
+	switch name {
+	case ProviderWeatherAPI:
+		return c.WeatherAPI.HTTP
+	case ProviderOpenMeteo:
+		return c.OpenMeteo.HTTP
+	}
+	return UpstreamConfig{}
+}
+
+// CacheConfig concerne le cache des réponses météo.
+type CacheConfig struct {
+	Enabled              bool          `yaml:"enabled" toml:"enabled"`
+	MaxEntries           int           `yaml:"max_entries" toml:"max_entries"`
+	TTLCurrent           time.Duration `yaml:"ttl_current" toml:"ttl_current"`
+	TTLForecast          time.Duration `yaml:"ttl_forecast" toml:"ttl_forecast"`
+	TTLAlerts            time.Duration `yaml:"ttl_alerts" toml:"ttl_alerts"`
+	StaleWhileRevalidate time.Duration `yaml:"stale_while_revalidate" toml:"stale_while_revalidate"`
+	StaleIfError         time.Duration `yaml:"stale_if_error" toml:"stale_if_error"`
+	DiskDir              string        `yaml:"disk_dir" toml:"disk_dir"` // vide = pas de cache disque ; dossier réservé au cache
+	DiskMaxBytes         int64         `yaml:"disk_max_bytes" toml:"disk_max_bytes"`
+	DiskCompactInterval  time.Duration `yaml:"disk_compact_interval" toml:"disk_compact_interval"` // purge des entrées périmées
+}
+
+// AlertsConfig regroupe les seuils des alertes dérivées.
+type AlertsConfig struct {
+	DefaultCity string  `yaml:"default_city" toml:"default_city"` // ville de /api/alerts sans paramètre
+	RainChance  int     `yaml:"rain_chance" toml:"rain_chance"`   // % à partir duquel on alerte
+	WindKph     float64 `yaml:"wind_kph" toml:"wind_kph"`         // vent max
+	HeatC       float64 `yaml:"heat_c" toml:"heat_c"`             // température max
+}
+
+// HealthConfig concerne la sonde de disponibilité des fournisseurs
+// (/api/health/ready).
+type HealthConfig struct {
+	ProbeInterval time.Duration `yaml:"probe_interval" toml:"probe_interval"` // durée de validité d'un résultat de sonde
+	ProbeTimeout  time.Duration `yaml:"probe_timeout" toml:"probe_timeout"`   // échéance d'une sonde
+}
+
+// LoggingConfig concerne les journaux.
+type LoggingConfig struct {
+	Level  string `yaml:"level" toml:"level"`   // debug, info, warn, error
+	Format string `yaml:"format" toml:"format"` // text, json
+}
+
+// Exporteurs de traces.
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+// TracingConfig concerne le traçage des requêtes (lu au démarrage).
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`         // none, stdout, otlp
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`         // collecteur OTLP/HTTP (protobuf)
+	ServiceName string  `yaml:"service_name" toml:"service_name"` // attribut service.name
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"` // part des nouvelles traces conservées
+}
+
+// AdminConfig concerne les endpoints d'administration, servis sur un port
+// séparé (admin.addr, lu au démarrage).
+type AdminConfig struct {
+	Addr  string `yaml:"addr" toml:"addr"`                 // hôte:port ; vide = pas d'écoute d'administration
+	Token string `yaml:"token" toml:"token" secret:"true"` // jeton Bearer ; vide = endpoints désactivés
+}
+
+// Default retourne la configuration par défaut.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:           8080,
+			FrontendDir:    "../frontend",
+			RequestTimeout: 10 * time.Second,
+
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:      true,
+			RPS:          5,
+			Burst:        20,
+			APIKeyHeader: "X-API-Key",
+			IdleTimeout:  10 * time.Minute,
+		},
+		Providers: ProvidersConfig{
+			Order:       []string{ProviderWeatherAPI},
+			Mode:        ModeChain,
+			Cooldown:    30 * time.Second,
+			HTTPTimeout: 5 * time.Second,
+			WeatherAPI: WeatherAPIConfig{
+				URL:         "http://api.weatherapi.com/v1/forecast.json",
+				QuotaPeriod: QuotaMonth,
+				HTTP:        defaultUpstream(),
+			},
+			OpenMeteo: OpenMeteoConfig{
+				ForecastURL:   "https://api.open-meteo.com/v1/forecast",
+				AirQualityURL: "https://air-quality-api.open-meteo.com/v1/air-quality",
+				GeocodingURL:  "https://geocoding-api.open-meteo.com/v1/search",
+				HTTP:          defaultUpstream(),
+			},
+		},
+		Cache: CacheConfig{
+			Enabled:              true,
+			MaxEntries:           1000,
+			TTLCurrent:           5 * time.Minute,
+			TTLForecast:          30 * time.Minute,
+			TTLAlerts:            10 * time.Minute,
+			StaleWhileRevalidate: time.Minute,
+			StaleIfError:         6 * time.Hour,
+			DiskMaxBytes:         64 << 20,
+			DiskCompactInterval:  10 * time.Minute,
+		},
+		Alerts: AlertsConfig{
+			DefaultCity: "Paris",
+			RainChance:  70,
+			WindKph:     50,
+			HeatC:       30,
+		},
+		Health: HealthConfig{
+			ProbeInterval: 30 * time.Second,
+			ProbeTimeout:  2 * time.Second,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingNone,
+			Endpoint:    "http://localhost:4318/v1/traces",
+			ServiceName: "weather-app",
+			SampleRatio: 1,
+		},
+		Admin: AdminConfig{
+			Addr: "127.0.0.1:8081",
+		},
+	}
+}
 
-	log.Printf("[config] loading .env from %s\n", path)
-	if err := godotenv.Load(path); err != nil {
-		return fmt.Errorf("unable to load env file (%s): %w", path, err)
+func defaultUpstream() UpstreamConfig {
+	return UpstreamConfig{
+		MaxRetries:     2,
+		RetryBaseDelay: 200 * time.Millisecond,
+		RetryMaxDelay:  2 * time.Second,
+
+		BreakerThreshold:      5,
+		BreakerOpenTimeout:    30 * time.Second,
+		BreakerHalfOpenProbes: 1,
+
+		RateBurst: 10,
+	}
+}
+
+// Validate vérifie la cohérence de la configuration et retourne toutes
+// les erreurs d'un coup.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		fail("server.port: %d is not a valid TCP port", c.Server.Port)
+	}
+	if c.Server.RequestTimeout < 0 {
+		fail("server.request_timeout: must not be negative")
+	}
+	for path, d := range c.Server.RouteTimeouts {
+		if !strings.HasPrefix(path, "/") || d < 0 {
+			fail("server.route_timeouts: %q=%s is invalid (expected a path and a non-negative duration)", path, d)
+		}
+	}
+	for name, d := range map[string]time.Duration{
+		"read_header_timeout": c.Server.ReadHeaderTimeout,
+		"read_timeout":        c.Server.ReadTimeout,
+		"write_timeout":       c.Server.WriteTimeout,
+		"idle_timeout":        c.Server.IdleTimeout,
+	} {
+		if d < 0 {
+			fail("server.%s: must not be negative", name)
+		}
+	}
+	// la réponse 504 doit pouvoir être écrite avant write_timeout
+	if w := c.Server.WriteTimeout; w > 0 {
+		if c.Server.RequestTimeout >= w {
+			fail("server.write_timeout: %s must exceed server.request_timeout (%s)", w, c.Server.RequestTimeout)
+		}
+		for path, d := range c.Server.RouteTimeouts {
+			if d >= w {
+				fail("server.write_timeout: %s must exceed server.route_timeouts[%s] (%s)", w, path, d)
+			}
+		}
+	}
+	if c.Server.MaxHeaderBytes < 0 {
+		fail("server.max_header_bytes: must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		fail("server.shutdown_timeout: must be positive")
+	}
+
+	rl := c.RateLimit
+	if err := rl.Route("").validate(); err != nil {
+		fail("rate_limit: %v", err)
+	}
+	for path, r := range rl.Routes {
+		if !strings.HasPrefix(path, "/") {
+			fail("rate_limit.routes: %q is not a path", path)
+		}
+		if err := r.validate(); err != nil {
+			fail("rate_limit.routes.%s: %v", path, err)
+		}
+	}
+	for _, proxy := range rl.TrustedProxies {
+		if _, err := ParseIPNet(proxy); err != nil {
+			fail("rate_limit.trusted_proxies: %v", err)
+		}
+	}
+	if rl.Enabled && rl.IdleTimeout <= 0 {
+		fail("rate_limit.idle_timeout: must be positive")
+	}
+
+	p := c.Providers
+	if len(p.Order) == 0 {
+		fail("providers.order: at least one provider is required")
+	}
+	for _, name := range p.Order {
+		switch name {
+		case ProviderWeatherAPI:
+			if len(p.WeatherAPI.Keys()) == 0 {
+				fail("providers.weatherapi.api_key: at least one key is required when weatherapi is enabled (WEATHER_API_KEY or WEATHER_API_KEYS)")
+			}
+			if p.WeatherAPI.KeyQuota < 0 {
+				fail("providers.weatherapi.key_quota: must not be negative")
+			}
+			switch p.WeatherAPI.QuotaPeriod {
+			case QuotaMonth, QuotaDay, QuotaHour:
+			default:
+				fail("providers.weatherapi.quota_period: %q is invalid (expected %s, %s or %s)", p.WeatherAPI.QuotaPeriod, QuotaMonth, QuotaDay, QuotaHour)
+			}
+			if err := checkURL(p.WeatherAPI.URL); err != nil {
+				fail("providers.weatherapi.url: %v (WEATHER_API_URL)", err)
+			}
+			errs = append(errs, p.WeatherAPI.HTTP.validate("providers.weatherapi.http")...)
+		case ProviderOpenMeteo:
+			if err := checkURL(p.OpenMeteo.ForecastURL); err != nil {
+				fail("providers.openmeteo.forecast_url: %v", err)
+			}
+			if err := checkURL(p.OpenMeteo.AirQualityURL); err != nil {
+				fail("providers.openmeteo.air_quality_url: %v", err)
+			}
+			if err := checkURL(p.OpenMeteo.GeocodingURL); err != nil {
+				fail("providers.openmeteo.geocoding_url: %v", err)
+			}
+			errs = append(errs, p.OpenMeteo.HTTP.validate("providers.openmeteo.http")...)
+		default:
+			fail("providers.order: unknown provider %q (expected %s or %s)", name, ProviderWeatherAPI, ProviderOpenMeteo)
+		}
+	}
+	if p.Mode != ModeChain && p.Mode != ModeEnsemble {
+		fail("providers.mode: %q is invalid (expected %s or %s)", p.Mode, ModeChain, ModeEnsemble)
+	}
+	if p.Cooldown < 0 {
+		fail("providers.cooldown: must not be negative")
+	}
+	if p.HTTPTimeout <= 0 {
+		fail("providers.http_timeout: must be positive")
+	}
+	for name, w := range p.EnsembleWeights {
+		if w < 0 {
+			fail("providers.ensemble_weights.%s: must not be negative", name)
+		}
+		if p.Mode == ModeEnsemble && !slices.Contains(p.Order, name) {
+			fail("providers.ensemble_weights.%s: provider is not in providers.order", name)
+		}
+	}
+	if p.Mode == ModeEnsemble && len(p.Order) > 0 {
+		// un fournisseur sans poids compte pour 1
+		weighted := false
+		for _, name := range p.Order {
+			if w, ok := p.EnsembleWeights[name]; !ok || w > 0 {
+				weighted = true
+			}
+		}
+		if !weighted {
+			fail("providers.ensemble_weights: at least one provider of providers.order must have a positive weight")
+		}
+	}
+
+	cc := c.Cache
+	if cc.Enabled && cc.MaxEntries <= 0 {
+		fail("cache.max_entries: must be positive when the cache is enabled")
+	}
+	if cc.TTLCurrent < 0 || cc.TTLForecast < 0 || cc.TTLAlerts < 0 {
+		fail("cache.ttl_*: must not be negative")
+	}
+	if cc.StaleWhileRevalidate < 0 || cc.StaleIfError < 0 {
+		fail("cache.stale_*: must not be negative")
+	}
+	if cc.DiskDir != "" && cc.DiskMaxBytes <= 0 {
+		fail("cache.disk_max_bytes: must be positive when cache.disk_dir is set")
+	}
+	if cc.DiskDir != "" && cc.DiskCompactInterval <= 0 {
+		fail("cache.disk_compact_interval: must be positive when cache.disk_dir is set")
+	}
+
+	if c.Alerts.RainChance < 0 || c.Alerts.RainChance > 100 {
+		fail("alerts.rain_chance: %d is not a percentage", c.Alerts.RainChance)
+	}
+
+	if c.Health.ProbeInterval <= 0 {
+		fail("health.probe_interval: must be positive")
+	}
+	if c.Health.ProbeTimeout <= 0 {
+		fail("health.probe_timeout: must be positive")
+	}
+
+	switch strings.ToLower(c.Logging.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		fail("logging.level: %q is invalid (expected debug, info, warn or error)", c.Logging.Level)
+	}
+	switch strings.ToLower(c.Logging.Format) {
+	case "text", "json":
+	default:
+		fail("logging.format: %q is invalid (expected text or json)", c.Logging.Format)
+	}
+	switch c.Tracing.Exporter {
+	case TracingNone, TracingStdout:
+	case TracingOTLP:
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("tracing.endpoint: %q is not a valid http(s) URL", c.Tracing.Endpoint)
+		}
+	default:
+		fail("tracing.exporter: %q is invalid (expected none, stdout or otlp)", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio: %v must be between 0 and 1", c.Tracing.SampleRatio)
 	}
 
-	log.Println("[config] .env loaded successfully")
+	if c.Admin.Addr != "" {
+		_, port, err := net.SplitHostPort(c.Admin.Addr)
+		if n, perr := strconv.Atoi(port); err != nil || perr != nil || n <= 0 || n > 65535 {
+			fail("admin.addr: %q is not a valid host:port", c.Admin.Addr)
+		} else if n == c.Server.Port {
+			fail("admin.addr: port %d is already used by server.port", n)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate vérifie le réglage HTTP d'un fournisseur ; path préfixe les erreurs.
+func (u UpstreamConfig) validate(path string) []error {
+	var errs []error
+	if u.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_retries: must not be negative", path))
+	}
+	if u.RetryBaseDelay < 0 {
+		errs = append(errs, fmt.Errorf("%s.retry_base_delay: must not be negative", path))
+	}
+	if u.RetryMaxDelay < u.RetryBaseDelay {
+		errs = append(errs, fmt.Errorf("%s.retry_max_delay: must not be lower than retry_base_delay", path))
+	}
+	if u.BreakerThreshold < 0 {
+		errs = append(errs, fmt.Errorf("%s.breaker_threshold: must not be negative", path))
+	}
+	if u.BreakerThreshold > 0 && u.BreakerOpenTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s.breaker_open_timeout: must be positive when the breaker is enabled", path))
+	}
+	if u.BreakerThreshold > 0 && u.BreakerHalfOpenProbes <= 0 {
+		errs = append(errs, fmt.Errorf("%s.breaker_half_open_probes: must be positive when the breaker is enabled", path))
+	}
+	if u.RateLimit < 0 {
+		errs = append(errs, fmt.Errorf("%s.rate_limit: must not be negative", path))
+	}
+	if u.RateLimit > 0 && u.RateBurst <= 0 {
+		errs = append(errs, fmt.Errorf("%s.rate_burst: must be positive when rate_limit is set", path))
+	}
+	return errs
+}
+
+// validate vérifie une limite de route.
+func (r RouteRate) validate() error {
+	if r.RPS < 0 {
+		return errors.New("rps must not be negative")
+	}
+	if r.RPS > 0 && r.Burst <= 0 {
+		return errors.New("burst must be positive when rps is set")
+	}
 	return nil
 }
 
-// GetWeatherAPIKey retourne la clé WeatherAPI depuis l'environnement.
-func GetWeatherAPIKey() (string, error) {
-	key := os.Getenv("WEATHER_API_KEY")
-	if key == "" {
-		return "", fmt.Errorf("WEATHER_API_KEY is not set")
+// ParseIPNet lit une adresse IP ou un réseau CIDR.
+func ParseIPNet(s string) (*net.IPNet, error) {
+	if _, n, err := net.ParseCIDR(s); err == nil {
+		return n, nil
 	}
-	return key, nil
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is neither an IP address nor a CIDR", s)
+	}
+	bits := 8 * len(ip.To4())
+	if bits == 0 {
+		bits = 8 * net.IPv6len
+	} else {
+		ip = ip.To4()
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// checkURL vérifie qu'une URL est absolue (http/https).
+func checkURL(raw string) error {
+	if raw == "" {
+		return errors.New("is not set")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q must be an absolute http(s) URL", raw)
+	}
+	return nil
 }
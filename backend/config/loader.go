@@ -0,0 +1,489 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"weather-app-backend/utils"
+)
+
+// Options décrit les sources de la configuration.
+type Options struct {
+	File     string   // fichier YAML/TOML (optionnel)
+	EnvFiles []string // fichiers .env : base puis surcouches, le dernier l'emporte
+
+	// Lookup lit l'environnement réel (os.LookupEnv par défaut).
+	Lookup func(key string) (string, bool)
+}
+
+// Origines possibles d'une valeur (voir Sources).
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+)
+
+// Sources associe à chaque champ (ex. "server.port") l'origine de sa
+// valeur : "default", "file:<chemin>", "env-file:<chemin> (VAR)" ou
+// "env (VAR)" ; VAR peut être VAR_FILE pour un secret lu dans un fichier.
+type Sources map[string]string
+
+// Load construit la configuration par couches, chaque couche l'emportant
+// clé par clé sur la précédente : valeurs par défaut, fichier YAML/TOML,
+// fichiers .env, puis variables d'environnement réelles. Les fichiers .env
+// ne modifient pas l'environnement du processus.
+//
+// Si seule la validation échoue, la configuration et ses sources sont tout
+// de même retournées (diagnostic, --print-config).
+func Load(opts Options) (*Config, Sources, error) {
+	cfg := Default()
+	src := make(Sources)
+	for _, f := range cfg.Fields() {
+		src[f.Path] = SourceDefault
+	}
+
+	if opts.File != "" {
+		keys, err := cfg.applyFile(opts.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		src.mark(keys, "file:"+opts.File)
+	}
+
+	fileEnv, fileOrigin, err := readEnvFiles(opts.EnvFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+	lookup := opts.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	fromFiles := func(key string) (string, bool) {
+		v, ok := fileEnv[key]
+		return v, ok
+	}
+	err = cfg.applyEnv(func(key string) (string, string, bool, error) {
+		v, origin, ok, err := fromLayer(lookup, key, SourceEnv)
+		if ok || err != nil {
+			return v, origin, ok, err
+		}
+		path := fileOrigin[key]
+		if path == "" {
+			path = fileOrigin[key+"_FILE"]
+		}
+		return fromLayer(fromFiles, key, "env-file:"+path)
+	}, src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// les secrets chargés ne doivent jamais apparaître dans les journaux
+	for _, secret := range cfg.secretValues() {
+		utils.RegisterSecret(secret)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, src, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, src, nil
+}
+
+// New est un raccourci de Load sur l'environnement réel.
+func New(configFile string, envFiles ...string) (*Config, error) {
+	cfg, _, err := Load(Options{File: configFile, EnvFiles: envFiles})
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// mark attribue origin aux champs présents dans keys (chemins "a.b.c" ;
+// une clé plus profonde, ex. une entrée de map, marque son champ parent).
+func (s Sources) mark(keys []string, origin string) {
+	for path := range s {
+		for _, key := range keys {
+			if key == path || strings.HasPrefix(key, path+".") {
+				s[path] = origin
+				break
+			}
+		}
+	}
+}
+
+// applyFile fusionne un fichier .yaml/.yml/.toml dans la configuration :
+// seules les clés présentes dans le fichier sont modifiées. Retourne ces clés.
+func (c *Config) applyFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		var tree map[string]any
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		return flattenKeys("", tree), nil
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		keys := make([]string, 0, len(md.Keys()))
+		for _, k := range md.Keys() {
+			keys = append(keys, k.String())
+		}
+		return keys, nil
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q (expected .yaml, .yml or .toml)", path, ext)
+	}
+}
+
+// flattenKeys liste les chemins "a.b.c" d'un document YAML décodé.
+func flattenKeys(prefix string, tree map[string]any) []string {
+	var keys []string
+	for k, v := range tree {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		keys = append(keys, path)
+		if sub, ok := v.(map[string]any); ok {
+			keys = append(keys, flattenKeys(path, sub)...)
+		}
+	}
+	return keys
+}
+
+// fromLayer lit key dans une couche (environnement ou fichiers .env), ou
+// le contenu du fichier désigné par key_FILE (secrets Docker/Kubernetes).
+func fromLayer(get func(string) (string, bool), key, origin string) (value, src string, ok bool, err error) {
+	v, hasValue := get(key)
+	hasValue = hasValue && strings.TrimSpace(v) != ""
+	path, hasFile := get(key + "_FILE")
+	hasFile = hasFile && strings.TrimSpace(path) != ""
+
+	switch {
+	case hasValue && hasFile:
+		return "", "", false, fmt.Errorf("both %s and %s_FILE are set (%s)", key, key, origin)
+	case hasFile:
+		data, err := os.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return "", "", false, fmt.Errorf("%s_FILE: %w", key, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), origin + " (" + key + "_FILE)", true, nil
+	case hasValue:
+		return v, origin + " (" + key + ")", true, nil
+	}
+	return "", "", false, nil
+}
+
+// readEnvFiles lit les fichiers .env dans l'ordre ; une clé d'un fichier
+// ultérieur (surcouche) remplace celle d'un fichier précédent. origin
+// indique le fichier d'où provient chaque clé.
+func readEnvFiles(paths []string) (values, origin map[string]string, err error) {
+	values = make(map[string]string)
+	origin = make(map[string]string)
+	for _, path := range paths {
+		env, err := godotenv.Read(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to load env file (%s): %w", path, err)
+		}
+		for k, v := range env {
+			// une surcouche KEY remplace un KEY_FILE antérieur, et inversement
+			counterpart := k + "_FILE"
+			if base, ok := strings.CutSuffix(k, "_FILE"); ok {
+				counterpart = base
+			}
+			if _, same := env[counterpart]; !same {
+				delete(values, counterpart)
+				delete(origin, counterpart)
+			}
+			values[k] = v
+			origin[k] = path
+		}
+	}
+	return values, origin, nil
+}
+
+// envVar associe une variable d'environnement à un champ de Config.
+type envVar struct {
+	key   string
+	field string // chemin du champ, pour Sources
+	apply func(c *Config, v string) error
+}
+
+// envVars liste les variables reconnues.
+var envVars = []envVar{
+	{"PORT", "server.port", intVar(func(c *Config) *int { return &c.Server.Port })},
+	{"FRONTEND_DIR", "server.frontend_dir", stringVar(func(c *Config) *string { return &c.Server.FrontendDir })},
+	{"REQUEST_TIMEOUT", "server.request_timeout", durationVar(func(c *Config) *time.Duration { return &c.Server.RequestTimeout })},
+	{"ROUTE_TIMEOUTS", "server.route_timeouts", routeTimeoutsVar},
+	{"SERVER_READ_HEADER_TIMEOUT", "server.read_header_timeout", durationVar(func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout })},
+	{"SERVER_READ_TIMEOUT", "server.read_timeout", durationVar(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{"SERVER_WRITE_TIMEOUT", "server.write_timeout", durationVar(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{"SERVER_IDLE_TIMEOUT", "server.idle_timeout", durationVar(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{"SERVER_MAX_HEADER_BYTES", "server.max_header_bytes", intVar(func(c *Config) *int { return &c.Server.MaxHeaderBytes })},
+	{"SHUTDOWN_TIMEOUT", "server.shutdown_timeout", durationVar(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+
+	{"RATE_LIMIT_ENABLED", "rate_limit.enabled", boolVar(func(c *Config) *bool { return &c.RateLimit.Enabled })},
+	{"RATE_LIMIT_RPS", "rate_limit.rps", floatVar(func(c *Config) *float64 { return &c.RateLimit.RPS })},
+	{"RATE_LIMIT_BURST", "rate_limit.burst", intVar(func(c *Config) *int { return &c.RateLimit.Burst })},
+	{"RATE_LIMIT_ROUTES", "rate_limit.routes", routesVar},
+	{"RATE_LIMIT_TRUSTED_PROXIES", "rate_limit.trusted_proxies", listVar(func(c *Config) *[]string { return &c.RateLimit.TrustedProxies })},
+	{"RATE_LIMIT_API_KEY_HEADER", "rate_limit.api_key_header", stringVar(func(c *Config) *string { return &c.RateLimit.APIKeyHeader })},
+	{"RATE_LIMIT_CLIENT_KEYS", "rate_limit.client_keys", secretListVar(func(c *Config) *[]string { return &c.RateLimit.ClientKeys })},
+	{"RATE_LIMIT_IDLE_TIMEOUT", "rate_limit.idle_timeout", durationVar(func(c *Config) *time.Duration { return &c.RateLimit.IdleTimeout })},
+
+	// WEATHER_PROVIDER (un seul) est conservé pour compatibilité ; WEATHER_PROVIDERS l'emporte.
+	{"WEATHER_PROVIDER", "providers.order", listVar(func(c *Config) *[]string { return &c.Providers.Order })},
+	{"WEATHER_PROVIDERS", "providers.order", listVar(func(c *Config) *[]string { return &c.Providers.Order })},
+	{"WEATHER_MODE", "providers.mode", stringVar(func(c *Config) *string { return &c.Providers.Mode })},
+	{"WEATHER_PROVIDER_COOLDOWN", "providers.cooldown", durationVar(func(c *Config) *time.Duration { return &c.Providers.Cooldown })},
+	{"WEATHER_ENSEMBLE_WEIGHTS", "providers.ensemble_weights", weightsVar},
+	{"HTTP_CLIENT_TIMEOUT", "providers.http_timeout", durationVar(func(c *Config) *time.Duration { return &c.Providers.HTTPTimeout })},
+	{"WEATHER_API_KEY", "providers.weatherapi.api_key", stringVar(func(c *Config) *string { return &c.Providers.WeatherAPI.APIKey })},
+	{"WEATHER_API_KEYS", "providers.weatherapi.api_keys", secretListVar(func(c *Config) *[]string { return &c.Providers.WeatherAPI.APIKeys })},
+	{"WEATHER_API_KEY_QUOTA", "providers.weatherapi.key_quota", intVar(func(c *Config) *int { return &c.Providers.WeatherAPI.KeyQuota })},
+	{"WEATHER_API_QUOTA_PERIOD", "providers.weatherapi.quota_period", stringVar(func(c *Config) *string { return &c.Providers.WeatherAPI.QuotaPeriod })},
+	{"WEATHER_API_URL", "providers.weatherapi.url", stringVar(func(c *Config) *string { return &c.Providers.WeatherAPI.URL })},
+	{"WEATHER_API_MAX_RETRIES", "providers.weatherapi.http.max_retries", intVar(func(c *Config) *int { return &c.Providers.WeatherAPI.HTTP.MaxRetries })},
+	{"WEATHER_API_RETRY_BASE_DELAY", "providers.weatherapi.http.retry_base_delay", durationVar(func(c *Config) *time.Duration { return &c.Providers.WeatherAPI.HTTP.RetryBaseDelay })},
+	{"WEATHER_API_RETRY_MAX_DELAY", "providers.weatherapi.http.retry_max_delay", durationVar(func(c *Config) *time.Duration { return &c.Providers.WeatherAPI.HTTP.RetryMaxDelay })},
+	{"WEATHER_API_BREAKER_THRESHOLD", "providers.weatherapi.http.breaker_threshold", intVar(func(c *Config) *int { return &c.Providers.WeatherAPI.HTTP.BreakerThreshold })},
+	{"WEATHER_API_BREAKER_OPEN_TIMEOUT", "providers.weatherapi.http.breaker_open_timeout", durationVar(func(c *Config) *time.Duration { return &c.Providers.WeatherAPI.HTTP.BreakerOpenTimeout })},
+	{"WEATHER_API_BREAKER_HALF_OPEN_PROBES", "providers.weatherapi.http.breaker_half_open_probes", intVar(func(c *Config) *int { return &c.Providers.WeatherAPI.HTTP.BreakerHalfOpenProbes })},
+	{"WEATHER_API_RATE_LIMIT", "providers.weatherapi.http.rate_limit", floatVar(func(c *Config) *float64 { return &c.Providers.WeatherAPI.HTTP.RateLimit })},
+	{"WEATHER_API_RATE_BURST", "providers.weatherapi.http.rate_burst", intVar(func(c *Config) *int { return &c.Providers.WeatherAPI.HTTP.RateBurst })},
+	{"OPEN_METEO_FORECAST_URL", "providers.openmeteo.forecast_url", stringVar(func(c *Config) *string { return &c.Providers.OpenMeteo.ForecastURL })},
+	{"OPEN_METEO_AIR_QUALITY_URL", "providers.openmeteo.air_quality_url", stringVar(func(c *Config) *string { return &c.Providers.OpenMeteo.AirQualityURL })},
+	{"OPEN_METEO_GEOCODING_URL", "providers.openmeteo.geocoding_url", stringVar(func(c *Config) *string { return &c.Providers.OpenMeteo.GeocodingURL })},
+	{"OPEN_METEO_MAX_RETRIES", "providers.openmeteo.http.max_retries", intVar(func(c *Config) *int { return &c.Providers.OpenMeteo.HTTP.MaxRetries })},
+	{"OPEN_METEO_RETRY_BASE_DELAY", "providers.openmeteo.http.retry_base_delay", durationVar(func(c *Config) *time.Duration { return &c.Providers.OpenMeteo.HTTP.RetryBaseDelay })},
+	{"OPEN_METEO_RETRY_MAX_DELAY", "providers.openmeteo.http.retry_max_delay", durationVar(func(c *Config) *time.Duration { return &c.Providers.OpenMeteo.HTTP.RetryMaxDelay })},
+	{"OPEN_METEO_BREAKER_THRESHOLD", "providers.openmeteo.http.breaker_threshold", intVar(func(c *Config) *int { return &c.Providers.OpenMeteo.HTTP.BreakerThreshold })},
+	{"OPEN_METEO_BREAKER_OPEN_TIMEOUT", "providers.openmeteo.http.breaker_open_timeout", durationVar(func(c *Config) *time.Duration { return &c.Providers.OpenMeteo.HTTP.BreakerOpenTimeout })},
+	{"OPEN_METEO_BREAKER_HALF_OPEN_PROBES", "providers.openmeteo.http.breaker_half_open_probes", intVar(func(c *Config) *int { return &c.Providers.OpenMeteo.HTTP.BreakerHalfOpenProbes })},
+	{"OPEN_METEO_RATE_LIMIT", "providers.openmeteo.http.rate_limit", floatVar(func(c *Config) *float64 { return &c.Providers.OpenMeteo.HTTP.RateLimit })},
+	{"OPEN_METEO_RATE_BURST", "providers.openmeteo.http.rate_burst", intVar(func(c *Config) *int { return &c.Providers.OpenMeteo.HTTP.RateBurst })},
+
+	{"CACHE_ENABLED", "cache.enabled", boolVar(func(c *Config) *bool { return &c.Cache.Enabled })},
+	{"CACHE_MAX_ENTRIES", "cache.max_entries", intVar(func(c *Config) *int { return &c.Cache.MaxEntries })},
+	{"CACHE_TTL_CURRENT", "cache.ttl_current", durationVar(func(c *Config) *time.Duration { return &c.Cache.TTLCurrent })},
+	{"CACHE_TTL_FORECAST", "cache.ttl_forecast", durationVar(func(c *Config) *time.Duration { return &c.Cache.TTLForecast })},
+	{"CACHE_TTL_ALERTS", "cache.ttl_alerts", durationVar(func(c *Config) *time.Duration { return &c.Cache.TTLAlerts })},
+	{"CACHE_STALE_WHILE_REVALIDATE", "cache.stale_while_revalidate", durationVar(func(c *Config) *time.Duration { return &c.Cache.StaleWhileRevalidate })},
+	{"CACHE_STALE_IF_ERROR", "cache.stale_if_error", durationVar(func(c *Config) *time.Duration { return &c.Cache.StaleIfError })},
+	{"CACHE_DISK_DIR", "cache.disk_dir", stringVar(func(c *Config) *string { return &c.Cache.DiskDir })},
+	{"CACHE_DISK_MAX_BYTES", "cache.disk_max_bytes", int64Var(func(c *Config) *int64 { return &c.Cache.DiskMaxBytes })},
+	{"CACHE_DISK_COMPACT_INTERVAL", "cache.disk_compact_interval", durationVar(func(c *Config) *time.Duration { return &c.Cache.DiskCompactInterval })},
+
+	{"ALERTS_DEFAULT_CITY", "alerts.default_city", stringVar(func(c *Config) *string { return &c.Alerts.DefaultCity })},
+	{"ALERTS_RAIN_CHANCE", "alerts.rain_chance", intVar(func(c *Config) *int { return &c.Alerts.RainChance })},
+	{"ALERTS_WIND_KPH", "alerts.wind_kph", floatVar(func(c *Config) *float64 { return &c.Alerts.WindKph })},
+	{"ALERTS_HEAT_C", "alerts.heat_c", floatVar(func(c *Config) *float64 { return &c.Alerts.HeatC })},
+
+	{"HEALTH_PROBE_INTERVAL", "health.probe_interval", durationVar(func(c *Config) *time.Duration { return &c.Health.ProbeInterval })},
+	{"HEALTH_PROBE_TIMEOUT", "health.probe_timeout", durationVar(func(c *Config) *time.Duration { return &c.Health.ProbeTimeout })},
+
+	{"LOG_LEVEL", "logging.level", stringVar(func(c *Config) *string { return &c.Logging.Level })},
+	{"LOG_FORMAT", "logging.format", stringVar(func(c *Config) *string { return &c.Logging.Format })},
+	{"TRACING_EXPORTER", "tracing.exporter", stringVar(func(c *Config) *string { return &c.Tracing.Exporter })},
+	{"TRACING_ENDPOINT", "tracing.endpoint", stringVar(func(c *Config) *string { return &c.Tracing.Endpoint })},
+	{"TRACING_SERVICE_NAME", "tracing.service_name", stringVar(func(c *Config) *string { return &c.Tracing.ServiceName })},
+	{"TRACING_SAMPLE_RATIO", "tracing.sample_ratio", floatVar(func(c *Config) *float64 { return &c.Tracing.SampleRatio })},
+
+	{"ADMIN_ADDR", "admin.addr", stringVar(func(c *Config) *string { return &c.Admin.Addr })},
+	{"ADMIN_TOKEN", "admin.token", stringVar(func(c *Config) *string { return &c.Admin.Token })},
+}
+
+// applyEnv applique les variables définies et non vides ; lookup retourne
+// aussi l'origine de la valeur, enregistrée dans src.
+func (c *Config) applyEnv(lookup func(string) (value, origin string, ok bool, err error), src Sources) error {
+	var errs []error
+	for _, ev := range envVars {
+		v, origin, ok, err := lookup(ev.key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := ev.apply(c, strings.TrimSpace(v)); err != nil {
+			errs = append(errs, fmt.Errorf("%s=%q (%s): %w", ev.key, v, origin, err))
+			continue
+		}
+		src[ev.field] = origin
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid environment:\n%w", err)
+	}
+	return nil
+}
+
+func stringVar(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func intVar(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func int64Var(field func(*Config) *int64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func floatVar(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return errors.New("not a number")
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func boolVar(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("not a boolean (true/false)")
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func durationVar(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.New(`not a duration (e.g. "30s", "5m")`)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// listVar lit une liste séparée par des virgules ("weatherapi,openmeteo").
+func listVar(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		var names []string
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				names = append(names, name)
+			}
+		}
+		*field(c) = names
+		return nil
+	}
+}
+
+// secretListVar lit des secrets séparés par des virgules ou des retours à
+// la ligne (fichier *_FILE), sans changer la casse.
+func secretListVar(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		var values []string
+		for _, s := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+		*field(c) = values
+		return nil
+	}
+}
+
+// weightsVar lit "weatherapi=2,openmeteo=1".
+func weightsVar(c *Config, v string) error {
+	weights := make(map[string]float64)
+	for _, pair := range strings.Split(v, ",") {
+		name, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("%q is not of the form provider=weight", pair)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("weight of %q is not a number", name)
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = w
+	}
+	c.Providers.EnsembleWeights = weights
+	return nil
+}
+
+// routeTimeoutsVar lit des échéances par route : "/api/weather=8s,/api/alerts=3s".
+func routeTimeoutsVar(c *Config, v string) error {
+	timeouts := make(map[string]time.Duration)
+	for _, pair := range strings.Split(v, ",") {
+		path, raw, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return fmt.Errorf("%q is not of the form path=duration", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("timeout of %q is not a duration (ex. 8s)", path)
+		}
+		timeouts[path] = d
+	}
+	c.Server.RouteTimeouts = timeouts
+	return nil
+}
+
+// routesVar lit des limites par route : "/api/weather=5:10,/api/alerts=1:5"
+// (requêtes par seconde:rafale).
+func routesVar(c *Config, v string) error {
+	routes := make(map[string]RouteRate)
+	for _, pair := range strings.Split(v, ",") {
+		path, raw, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return fmt.Errorf("%q is not of the form path=rps:burst", pair)
+		}
+		rawRPS, rawBurst, ok := strings.Cut(raw, ":")
+		if !ok {
+			return fmt.Errorf("%q is not of the form path=rps:burst", pair)
+		}
+		rps, err := strconv.ParseFloat(strings.TrimSpace(rawRPS), 64)
+		if err != nil {
+			return fmt.Errorf("rps of %q is not a number", path)
+		}
+		burst, err := strconv.Atoi(strings.TrimSpace(rawBurst))
+		if err != nil {
+			return fmt.Errorf("burst of %q is not an integer", path)
+		}
+		routes[path] = RouteRate{RPS: rps, Burst: burst}
+	}
+	c.RateLimit.Routes = routes
+	return nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"weather-app-backend/utils"
+)
+
+// Redacted remplace la valeur des champs secrets à l'affichage.
+const Redacted = utils.Redacted
+
+// Field est une valeur de configuration à plat, prête à afficher.
+type Field struct {
+	Path   string `json:"path"`  // ex. "providers.weatherapi.api_key"
+	Value  string `json:"value"` // masquée (Redacted) si Secret et non vide
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// Fields liste les champs de la configuration dans l'ordre de déclaration.
+// Les champs marqués `secret:"true"` sont masqués.
+func (c *Config) Fields() []Field {
+	var fields []Field
+	walkFields("", reflect.ValueOf(c).Elem(), &fields)
+	for i, f := range fields {
+		if f.Secret && f.Value != "" {
+			fields[i].Value = Redacted
+		}
+	}
+	return fields
+}
+
+// secretValues retourne les valeurs en clair des champs secrets.
+func (c *Config) secretValues() []string {
+	var fields []Field
+	walkFields("", reflect.ValueOf(c).Elem(), &fields)
+	var values []string
+	for _, f := range fields {
+		if f.Secret && f.Value != "" {
+			// les listes (pool de clés) sont jointes par des virgules
+			values = append(values, strings.Split(f.Value, ",")...)
+		}
+	}
+	return values
+}
+
+// walkFields aplatit v en champs (valeurs en clair).
+func walkFields(prefix string, v reflect.Value, out *[]Field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Duration(0)) {
+			walkFields(path, fv, out)
+			continue
+		}
+
+		*out = append(*out, Field{Path: path, Value: formatValue(fv), Secret: sf.Tag.Get("secret") == "true"})
+	}
+}
+
+// formatValue affiche une valeur comme on l'écrirait dans l'environnement.
+func formatValue(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String()
+	case []string:
+		return strings.Join(x, ",")
+	case map[string]float64:
+		pairs := make([]string, 0, len(x))
+		for k, w := range x {
+			pairs = append(pairs, fmt.Sprintf("%s=%g", k, w))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	case map[string]time.Duration:
+		pairs := make([]string, 0, len(x))
+		for k, d := range x {
+			pairs = append(pairs, k+"="+d.String())
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	case map[string]RouteRate:
+		pairs := make([]string, 0, len(x))
+		for k, r := range x {
+			pairs = append(pairs, fmt.Sprintf("%s=%g:%d", k, r.RPS, r.Burst))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// WriteEffective écrit la configuration effective (secrets masqués) avec
+// l'origine de chaque valeur.
+func WriteEffective(w io.Writer, cfg *Config, src Sources) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range cfg.Fields() {
+		origin := src[f.Path]
+		if origin == "" {
+			origin = SourceDefault
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Path, f.Value, origin)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"weather-app-backend/utils"
+)
+
+// DefaultWatchInterval est la période de vérification des fichiers surveillés.
+const DefaultWatchInterval = 2 * time.Second
+
+// Reloader recharge la configuration quand un fichier source change ou sur
+// SIGHUP. Une configuration invalide est ignorée : l'ancienne reste active.
+type Reloader struct {
+	opts   Options
+	apply  func(*Config) error
+	logger *slog.Logger
+
+	mu      sync.Mutex // sérialise les rechargements
+	current atomic.Pointer[Config]
+	stamps  map[string]fileStamp
+}
+
+// fileStamp identifie une version d'un fichier surveillé.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader crée un Reloader à partir de la configuration initiale ;
+// apply reçoit chaque nouvelle configuration validée ; logger peut être nil.
+func NewReloader(opts Options, initial *Config, apply func(*Config) error, logger *slog.Logger) *Reloader {
+	r := &Reloader{opts: opts, apply: apply, logger: utils.OrDiscard(logger)}
+	r.current.Store(initial)
+	r.stamps = r.snapshot()
+	return r
+}
+
+// Current retourne la configuration active.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Reload relit les sources, valide puis applique la configuration. En cas
+// d'échec, l'erreur est journalisée et l'ancienne configuration conservée.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stamps = r.snapshot()
+	cfg, _, err := Load(r.opts)
+	if err != nil {
+		r.logger.Error("reload rejected, keeping previous configuration", "error", err)
+		return err
+	}
+	if err := r.apply(cfg); err != nil {
+		r.logger.Error("reload not applied, keeping previous configuration", "error", err)
+		return err
+	}
+
+	// les échéances des routes, shutdown_timeout et logging.level sont relus
+	// à chaud ; le reste de server.*, logging.format, tracing et admin.addr
+	// ne sont lus qu'au démarrage
+	if old := r.current.Swap(cfg); old != nil && (old.Server.Listener() != cfg.Server.Listener() ||
+		old.Logging.Format != cfg.Logging.Format || old.Tracing != cfg.Tracing || old.Admin.Addr != cfg.Admin.Addr) {
+		r.logger.Warn("server settings changed, a restart is required for them to take effect")
+	}
+	r.logger.Info("configuration reloaded")
+	return nil
+}
+
+// Run surveille les fichiers (toutes les interval) et SIGHUP jusqu'à
+// l'annulation de ctx.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.logger.Info("SIGHUP received, reloading")
+			_ = r.Reload()
+		case <-ticker.C:
+			if r.changed() {
+				r.logger.Info("config file changed, reloading")
+				_ = r.Reload()
+			}
+		}
+	}
+}
+
+// files retourne les fichiers surveillés.
+func (r *Reloader) files() []string {
+	files := append([]string(nil), r.opts.EnvFiles...)
+	if r.opts.File != "" {
+		files = append(files, r.opts.File)
+	}
+	return files
+}
+
+// snapshot relève l'état courant des fichiers (absent = zéro).
+func (r *Reloader) snapshot() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, path := range r.files() {
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+func (r *Reloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.snapshot()
+	if len(now) != len(r.stamps) {
+		return true
+	}
+	for path, stamp := range now {
+		if r.stamps[path] != stamp {
+			return true
+		}
+	}
+	return false
+}
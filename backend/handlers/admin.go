@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"weather-app-backend/config"
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+	"weather-app-backend/utils"
+)
+
+// startedAt est la date de démarrage du processus (uptime de /admin/build).
+var startedAt = time.Now()
+
+// RequireAdmin protège un endpoint d'administration par le jeton Bearer
+// admin.token (relu à chaque requête pour suivre les rechargements). Sans
+// jeton configuré, l'endpoint n'existe pas (404).
+func RequireAdmin(svc *services.WeatherService, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := svc.Config().Admin.Token
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Authentification requise."})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// AdminHandler regroupe les endpoints de l'écoute d'administration
+// (admin.addr), tous protégés par RequireAdmin :
+//
+//	GET    /admin/build             version, commit, Go, état du runtime
+//	GET    /admin/config            configuration effective (secrets masqués)
+//	GET    /admin/cache?prefix=     statistiques et entrées du cache
+//	DELETE /admin/cache?key=        purge d'une entrée
+//	GET    /admin/providers         santé, disjoncteurs et activité des fournisseurs
+//	GET    /admin/keys              utilisation des clés WeatherAPI
+//	       /debug/pprof/            profilage (net/http/pprof)
+func AdminHandler(svc *services.WeatherService) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/build", adminBuild)
+	mux.HandleFunc("GET /admin/config", adminConfig(svc))
+	mux.HandleFunc("GET /admin/cache", adminCache(svc))
+	mux.HandleFunc("DELETE /admin/cache", adminPurgeCache(svc))
+	mux.HandleFunc("GET /admin/providers", adminProviders(svc))
+	mux.HandleFunc("GET /admin/keys", adminKeys(svc))
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return RequireAdmin(svc, mux.ServeHTTP)
+}
+
+func adminKeys(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, struct {
+			Keys []services.KeyUsage `json:"keys"`
+		}{Keys: svc.KeyUsage()})
+	}
+}
+
+// buildInfo est la réponse de /admin/build.
+type buildInfo struct {
+	Path       string    `json:"path"`
+	Version    string    `json:"version"` // "(devel)" hors d'un go install versionné
+	Commit     string    `json:"commit,omitempty"`
+	CommitTime string    `json:"commit_time,omitempty"`
+	Modified   bool      `json:"modified,omitempty"` // arbre de travail modifié au build
+	GoVersion  string    `json:"go_version"`
+	StartedAt  time.Time `json:"started_at"`
+	Uptime     string    `json:"uptime"`
+	Runtime    struct {
+		Goroutines int    `json:"goroutines"`
+		GOMAXPROCS int    `json:"gomaxprocs"`
+		HeapAlloc  uint64 `json:"heap_alloc_bytes"`
+		Sys        uint64 `json:"sys_bytes"`
+		NumGC      uint32 `json:"num_gc"`
+	} `json:"runtime"`
+}
+
+func adminBuild(w http.ResponseWriter, r *http.Request) {
+	info := buildInfo{GoVersion: runtime.Version(), StartedAt: startedAt, Uptime: time.Since(startedAt).Round(time.Second).String()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Path, info.Version = bi.Main.Path, bi.Main.Version
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Commit = s.Value
+			case "vcs.time":
+				info.CommitTime = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	info.Runtime.Goroutines = runtime.NumGoroutine()
+	info.Runtime.GOMAXPROCS = runtime.GOMAXPROCS(0)
+	info.Runtime.HeapAlloc, info.Runtime.Sys, info.Runtime.NumGC = mem.HeapAlloc, mem.Sys, mem.NumGC
+	writeAdminJSON(w, http.StatusOK, info)
+}
+
+func adminConfig(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, struct {
+			Fields []config.Field `json:"fields"`
+		}{Fields: svc.Config().Fields()})
+	}
+}
+
+func adminCache(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats, ok := svc.CacheStats()
+		if !ok {
+			writeAdminJSON(w, http.StatusNotFound, models.ErrorResponse{Error: "Le cache est désactivé."})
+			return
+		}
+		items, _ := svc.CacheItems(r.URL.Query().Get("prefix"))
+		writeAdminJSON(w, http.StatusOK, struct {
+			Stats   services.CacheStats  `json:"stats"`
+			Entries []services.CacheItem `json:"entries"`
+		}{Stats: stats, Entries: items})
+	}
+}
+
+func adminPurgeCache(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		if key == "" {
+			writeAdminJSON(w, http.StatusBadRequest, models.ErrorResponse{Error: "Le paramètre 'key' est obligatoire."})
+			return
+		}
+		if !svc.PurgeCache(key) {
+			writeAdminJSON(w, http.StatusNotFound, models.ErrorResponse{Error: "Aucune entrée pour cette clé."})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func adminProviders(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		providers, fallbacks := svc.ProvidersHealth()
+		writeAdminJSON(w, http.StatusOK, struct {
+			Mode      string                    `json:"mode"` // chain ou ensemble
+			Providers []services.ProviderHealth `json:"providers"`
+			Fallbacks int                       `json:"fallbacks"`
+			Upstream  []utils.UpstreamStats     `json:"upstream"`
+		}{Mode: svc.Config().Providers.Mode, Providers: providers, Fallbacks: fallbacks, Upstream: svc.UpstreamStats()})
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -7,20 +7,23 @@ import (
 	"weather-app-backend/services"
 )
 
-// AlertsHandler gère GET /api/alerts?city=Paris
-func AlertsHandler(w http.ResponseWriter, r *http.Request) {
-	city := r.URL.Query().Get("city")
-	if city == "" {
-		// ville par défaut, tu peux mettre "Paris" ou autre
-		city = "Paris"
-	}
+// AlertsHandler gère GET /api/v1/alerts/{location}, GET /api/v1/alerts et
+// l'ancienne route GET /api/alerts?city=Paris.
+func AlertsHandler(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		city := location(r)
+		if city == "" {
+			// ville par défaut (alerts.default_city)
+			city = svc.Config().Alerts.DefaultCity
+		}
 
-	alerts, err := services.GetGlobalWeatherAlerts(r.Context(), city)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+		alerts, err := svc.GetGlobalWeatherAlerts(r.Context(), city)
+		if err != nil {
+			writeWeatherError(w, r, err)
+			return
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(alerts)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(alerts)
+	}
 }
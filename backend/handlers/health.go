@@ -3,13 +3,71 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+
+	"weather-app-backend/services"
+	"weather-app-backend/utils"
 )
 
 type health struct {
-	Status string `json:"status"`
+	Status    string                    `json:"status"`
+	Providers []services.ProviderHealth `json:"providers,omitempty"`
+	Fallbacks int                       `json:"fallbacks"`
+	Cache     *services.CacheStats      `json:"cache,omitempty"`
+	Upstream  []utils.UpstreamStats     `json:"upstream,omitempty"`
+}
+
+func HealthHandler(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		providers, fallbacks := svc.ProvidersHealth()
+
+		// "degraded" si aucun fournisseur n'est actuellement utilisable
+		status := "degraded"
+		for _, p := range providers {
+			if p.Healthy {
+				status = "ok"
+				break
+			}
+		}
+		if len(providers) == 0 {
+			status = "ok"
+		}
+
+		resp := health{
+			Status:    status,
+			Providers: providers,
+			Fallbacks: fallbacks,
+			Upstream:  svc.UpstreamStats(),
+		}
+		if stats, ok := svc.CacheStats(); ok {
+			resp.Cache = &stats
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// LiveHandler répond tant que le processus sert des requêtes (liveness) :
+// il ne dépend d'aucun fournisseur, pour qu'une panne en amont ne fasse pas
+// redémarrer l'instance.
+func LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}
 }
 
-func HealthHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(health{Status: "ok"})
+// ReadyHandler indique si l'instance peut recevoir du trafic (readiness) :
+// 200 si elle est prête, 503 sinon, avec l'état de chaque composant.
+func ReadyHandler(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ready := svc.Readiness(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if !ready.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(ready)
+	}
 }
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"weather-app-backend/services"
+	"weather-app-backend/utils"
+)
+
+// HTTPMetrics mesure les requêtes servies : nombre et durée par route et
+// statut, requêtes en cours.
+type HTTPMetrics struct {
+	inFlight atomic.Int64
+
+	mu     sync.Mutex
+	series map[routeStatus]*utils.Histogram
+}
+
+// routeStatus identifie une série : route déclarée (pas le chemin reçu,
+// pour borner le nombre de séries) et statut.
+type routeStatus struct {
+	route  string
+	status int
+}
+
+// NewHTTPMetrics crée des compteurs vides.
+func NewHTTPMetrics() *HTTPMetrics {
+	return &HTTPMetrics{series: make(map[routeStatus]*utils.Histogram)}
+}
+
+// Instrument mesure les requêtes de la route.
+func (m *HTTPMetrics) Instrument(route string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.inFlight.Add(1)
+			defer m.inFlight.Add(-1)
+
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			m.histogram(routeStatus{route: route, status: rec.Status()}).ObserveDuration(time.Since(start))
+		})
+	}
+}
+
+func (m *HTTPMetrics) histogram(key routeStatus) *utils.Histogram {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.series[key]
+	if !ok {
+		h = utils.NewHistogram(utils.LatencyBuckets)
+		m.series[key] = h
+	}
+	return h
+}
+
+// snapshot retourne les séries triées par route puis statut.
+func (m *HTTPMetrics) snapshot() ([]routeStatus, []utils.HistogramSnapshot) {
+	m.mu.Lock()
+	keys := make([]routeStatus, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].status < keys[j].status
+	})
+	snaps := make([]utils.HistogramSnapshot, len(keys))
+	for i, key := range keys {
+		snaps[i] = m.histogram(key).Snapshot()
+	}
+	return keys, snaps
+}
+
+// MetricsHandler expose au format texte de Prometheus les mesures HTTP,
+// l'activité des fournisseurs, les erreurs par type et le cache.
+func MetricsHandler(svc *services.WeatherService, m *HTTPMetrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		mw := utils.NewMetricsWriter(w)
+
+		writeHTTPMetrics(mw, m)
+		writeUpstreamMetrics(mw, svc)
+		writeServiceMetrics(mw, svc)
+
+		if err := mw.Flush(); err != nil {
+			utils.LoggerFrom(r.Context()).WarnContext(r.Context(), "writing metrics failed", "error", err)
+		}
+	}
+}
+
+func writeHTTPMetrics(mw *utils.MetricsWriter, m *HTTPMetrics) {
+	keys, snaps := m.snapshot()
+	labels := func(key routeStatus) []utils.Label {
+		return []utils.Label{{Name: "route", Value: key.route}, {Name: "status", Value: strconv.Itoa(key.status)}}
+	}
+
+	mw.Family("weather_http_requests_total", "counter", "Requêtes HTTP servies, par route et statut.")
+	for i, key := range keys {
+		mw.Sample("weather_http_requests_total", float64(snaps[i].Count), labels(key)...)
+	}
+	mw.Family("weather_http_request_duration_seconds", "histogram", "Durée des requêtes HTTP, par route et statut.")
+	for i, key := range keys {
+		mw.Histogram("weather_http_request_duration_seconds", snaps[i], labels(key)...)
+	}
+	mw.Family("weather_http_requests_in_flight", "gauge", "Requêtes HTTP en cours.")
+	mw.Sample("weather_http_requests_in_flight", float64(m.inFlight.Load()))
+}
+
+func writeUpstreamMetrics(mw *utils.MetricsWriter, svc *services.WeatherService) {
+	upstreams := svc.UpstreamStats()
+	provider := func(u utils.UpstreamStats) utils.Label { return utils.Label{Name: "provider", Value: u.Name} }
+
+	mw.Family("weather_upstream_requests_total", "counter", "Tentatives envoyées aux fournisseurs, par statut HTTP (error = sans réponse).")
+	for _, u := range upstreams {
+		statuses := make([]string, 0, len(u.Responses))
+		for status := range u.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			mw.Sample("weather_upstream_requests_total", float64(u.Responses[status]), provider(u), utils.Label{Name: "status", Value: status})
+		}
+	}
+	mw.Family("weather_upstream_request_duration_seconds", "histogram", "Durée des tentatives envoyées aux fournisseurs (jusqu'aux en-têtes).")
+	for _, u := range upstreams {
+		mw.Histogram("weather_upstream_request_duration_seconds", u.Latency, provider(u))
+	}
+	mw.Family("weather_upstream_retries_total", "counter", "Nouvelles tentatives vers les fournisseurs.")
+	for _, u := range upstreams {
+		mw.Sample("weather_upstream_retries_total", float64(u.Retries), provider(u))
+	}
+	mw.Family("weather_upstream_rate_limited_total", "counter", "Appels refusés par la limite de débit sortante.")
+	for _, u := range upstreams {
+		mw.Sample("weather_upstream_rate_limited_total", float64(u.RateLimit.Rejected), provider(u))
+	}
+	mw.Family("weather_upstream_breaker_state", "gauge", "État du disjoncteur de chaque fournisseur (1 pour l'état courant).")
+	for _, u := range upstreams {
+		for _, state := range []string{utils.BreakerClosed, utils.BreakerOpen, utils.BreakerHalfOpen, utils.BreakerDisabled} {
+			v := 0.0
+			if u.Breaker.State == state {
+				v = 1
+			}
+			mw.Sample("weather_upstream_breaker_state", v, provider(u), utils.Label{Name: "state", Value: state})
+		}
+	}
+}
+
+func writeServiceMetrics(mw *utils.MetricsWriter, svc *services.WeatherService) {
+	counts := svc.ErrorCounts()
+	types := make([]string, 0, len(counts))
+	for typ := range counts {
+		types = append(types, string(typ))
+	}
+	sort.Strings(types)
+	mw.Family("weather_errors_total", "counter", "Erreurs retournées par le service, par type.")
+	for _, typ := range types {
+		mw.Sample("weather_errors_total", float64(counts[services.WeatherErrorType(typ)]), utils.Label{Name: "type", Value: typ})
+	}
+
+	providers, fallbacks := svc.ProvidersHealth()
+	mw.Family("weather_provider_healthy", "gauge", "1 si le fournisseur est utilisable, 0 s'il est en pause après des échecs.")
+	for _, p := range providers {
+		v := 0.0
+		if p.Healthy {
+			v = 1
+		}
+		mw.Sample("weather_provider_healthy", v, utils.Label{Name: "provider", Value: p.Name})
+	}
+	mw.Family("weather_provider_fallbacks_total", "counter", "Bascules vers un fournisseur de secours.")
+	mw.Sample("weather_provider_fallbacks_total", float64(fallbacks))
+
+	stats, ok := svc.CacheStats()
+	if !ok {
+		return
+	}
+	mw.Family("weather_cache_requests_total", "counter", "Lectures du cache, par résultat.")
+	mw.Sample("weather_cache_requests_total", float64(stats.Hits), utils.Label{Name: "result", Value: "hit"})
+	mw.Sample("weather_cache_requests_total", float64(stats.StaleHits), utils.Label{Name: "result", Value: "stale_hit"})
+	mw.Sample("weather_cache_requests_total", float64(stats.Misses), utils.Label{Name: "result", Value: "miss"})
+	mw.Family("weather_cache_hit_ratio", "gauge", "Part des lectures servies par le cache (entrées périmées comprises).")
+	mw.Sample("weather_cache_hit_ratio", stats.HitRatio())
+	mw.Family("weather_cache_entries", "gauge", "Entrées en cache.")
+	mw.Sample("weather_cache_entries", float64(stats.Entries))
+	mw.Family("weather_cache_evictions_total", "counter", "Entrées évincées du cache.")
+	mw.Sample("weather_cache_evictions_total", float64(stats.Evictions))
+}
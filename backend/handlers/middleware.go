@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+	"weather-app-backend/utils"
+)
+
+// Middleware enveloppe un handler.
+type Middleware func(http.Handler) http.Handler
+
+// Chain applique mws à h ; le premier middleware est le plus externe.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// RequestIDHeader porte l'identifiant de requête, reçu ou généré.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID borne les identifiants acceptés d'un client ou d'un proxy.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID reprend l'en-tête X-Request-ID (s'il est valide) ou en génère
+// un, le renvoie dans la réponse et l'attache au contexte : les journaux
+// des services le reprennent (request_id=…).
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(utils.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog attache logger au contexte de la requête (les middlewares et
+// handlers suivants le reprennent) et journalise chaque requête : méthode,
+// chemin, statut, taille de la réponse et durée.
+func AccessLog(logger *slog.Logger) Middleware {
+	logger = utils.OrDiscard(logger).With("component", "http")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			ctx := utils.WithLogger(r.Context(), logger)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			logger.InfoContext(ctx, "request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.Status(),
+				"bytes", rec.bytes,
+				"latency", time.Since(start),
+				"remote", r.RemoteAddr,
+			)
+		})
+	}
+}
+
+// Recover transforme une panique d'un handler en réponse 500 JSON (si la
+// réponse n'est pas déjà commencée) et la journalise avec la pile d'appels.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			utils.LoggerFrom(r.Context()).ErrorContext(r.Context(), "panic in handler",
+				"method", r.Method, "path", r.URL.Path, "panic", fmt.Sprint(v), "stack", string(debug.Stack()))
+			if rec.status != 0 {
+				return
+			}
+			rec.Header().Set("Content-Type", "application/json")
+			rec.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(rec).Encode(models.ErrorResponse{
+				Error: "Erreur inattendue côté serveur.",
+			})
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// Trace ouvre un span serveur par requête de la route, enfant de la trace
+// reçue dans traceparent si l'en-tête est valide : le service, les
+// fournisseurs et les appels HTTP sortants y ajoutent leurs spans (et
+// propagent traceparent). tp nil : aucun span.
+func Trace(tp trace.TracerProvider, route string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if tp == nil {
+			return next
+		}
+		tracer := tp.Tracer(utils.TracerName)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := utils.Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+			if id := utils.RequestID(ctx); id != "" {
+				span.SetAttributes(attribute.String("request_id", id))
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			span.SetAttributes(attribute.Int("http.response.status_code", rec.Status()))
+			if rec.Status() >= 500 {
+				utils.SpanError(span, fmt.Errorf("%d %s", rec.Status(), http.StatusText(rec.Status())))
+			}
+		})
+	}
+}
+
+// Deprecated signale une route remplacée : en-têtes Deprecation (date de
+// l'annonce, RFC 9745), Sunset (date de retrait, RFC 8594) et Link vers la
+// route qui la remplace pour cette requête (successor ; "" = pas de lien).
+func Deprecated(since, sunset time.Time, successor func(*http.Request) string) Middleware {
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	sunsetAt := sunset.UTC().Format(http.TimeFormat)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunsetAt)
+			if link := successor(r); link != "" {
+				w.Header().Set("Link", "<"+link+`>; rel="successor-version"`)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Timeout borne la durée des requêtes de la route (server.request_timeout
+// ou server.route_timeouts, relus à chaque requête) par une échéance sur le
+// contexte : les appels aux fournisseurs s'arrêtent à l'échéance et le
+// handler répond 504.
+func Timeout(svc *services.WeatherService, route string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := svc.Config().Server.RouteTimeout(route)
+			if d <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// writeTimeout répond 504 si l'échéance de la requête est dépassée ; il
+// retourne false sinon.
+func writeTimeout(w http.ResponseWriter, r *http.Request) bool {
+	if r.Context().Err() != context.DeadlineExceeded {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusGatewayTimeout)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error: "La requête a pris trop de temps. Réessaie plus tard.",
+	})
+	return true
+}
+
+// statusRecorder mémorise le statut et la taille de la réponse.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += int64(n)
+	return n, err
+}
+
+// Status retourne le statut envoyé (200 si le handler n'a rien écrit).
+func (s *statusRecorder) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
+
+// Unwrap permet à http.ResponseController d'atteindre le writer d'origine.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
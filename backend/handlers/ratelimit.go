@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"weather-app-backend/config"
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+	"weather-app-backend/utils"
+)
+
+// ClientLimiter limite le débit de chaque client (seau à jetons par client
+// et par route). La configuration (rate_limit) est relue à chaque requête
+// pour suivre les rechargements ; les seaux inactifs depuis idle_timeout
+// sont oubliés.
+type ClientLimiter struct {
+	svc *services.WeatherService
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*clientBucket
+	lastSweep time.Time
+
+	proxiesMu  sync.Mutex
+	proxiesKey string // trusted_proxies correspondant à proxies
+	proxies    []*net.IPNet
+}
+
+// clientBucket est le seau d'un client sur une route.
+type clientBucket struct {
+	tokens   float64
+	last     time.Time
+	lastSeen time.Time
+}
+
+// NewClientLimiter crée un limiteur vide.
+func NewClientLimiter(svc *services.WeatherService) *ClientLimiter {
+	return &ClientLimiter{svc: svc, now: time.Now, buckets: make(map[string]*clientBucket)}
+}
+
+// Limit applique la limite de la route à next. Chaque réponse porte les
+// en-têtes RateLimit-Limit, RateLimit-Remaining et RateLimit-Reset ; au-delà
+// de la limite, la réponse est 429 avec Retry-After.
+func (l *ClientLimiter) Limit(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := l.svc.Config().RateLimit
+		rate := cfg.Route(route)
+		if !cfg.Enabled || rate.RPS <= 0 {
+			next(w, r)
+			return
+		}
+
+		client := l.clientID(r, cfg)
+		allowed, remaining, reset := l.take(route+"|"+client, rate, cfg.IdleTimeout)
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(rate.Burst))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(reset))
+		if !allowed {
+			utils.LoggerFrom(r.Context()).WarnContext(r.Context(), "rate limit exceeded", "route", route, "client", client)
+			w.Header().Set("Retry-After", strconv.Itoa(reset))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Trop de requêtes. Réessaie dans quelques secondes.",
+			})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// take consomme un jeton du seau key ; reset est le délai (en secondes)
+// avant le prochain jeton si le seau est vide, avant qu'il soit plein sinon.
+func (l *ClientLimiter) take(key string, rate config.RouteRate, idle time.Duration) (allowed bool, remaining, reset int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now, idle)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &clientBucket{tokens: float64(rate.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(b.tokens+now.Sub(b.last).Seconds()*rate.RPS, float64(rate.Burst))
+	b.last, b.lastSeen = now, now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	}
+	remaining = int(b.tokens)
+	missing := float64(rate.Burst) - b.tokens
+	if !allowed {
+		missing = 1 - b.tokens
+	}
+	return allowed, remaining, int(math.Ceil(missing / rate.RPS))
+}
+
+// sweep oublie les seaux inactifs, au plus une fois par demi idle ; mu doit
+// être tenu.
+func (l *ClientLimiter) sweep(now time.Time, idle time.Duration) {
+	if now.Sub(l.lastSweep) < idle/2 {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Clients retourne le nombre de seaux suivis.
+func (l *ClientLimiter) Clients() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// clientID identifie le client : "key:…" pour une clé connue, sinon "ip:…".
+func (l *ClientLimiter) clientID(r *http.Request, cfg config.RateLimitConfig) string {
+	if cfg.APIKeyHeader != "" {
+		if given := r.Header.Get(cfg.APIKeyHeader); given != "" {
+			for i, key := range cfg.ClientKeys {
+				if subtle.ConstantTimeCompare([]byte(given), []byte(key)) == 1 {
+					return "key:" + strconv.Itoa(i+1)
+				}
+			}
+		}
+	}
+	return "ip:" + l.clientIP(r, cfg.TrustedProxies)
+}
+
+// clientIP retourne l'adresse du client : celle de la connexion, ou, si
+// elle vient d'un proxy de confiance, la dernière adresse non fiable de
+// X-Forwarded-For (les entrées de gauche peuvent être forgées).
+func (l *ClientLimiter) clientIP(r *http.Request, trusted []string) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	proxies := l.trustedProxies(trusted)
+	if !inNets(remote, proxies) {
+		return remote
+	}
+
+	var hops []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		for _, ip := range strings.Split(h, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				hops = append(hops, ip)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if net.ParseIP(hops[i]) == nil {
+			break
+		}
+		if !inNets(hops[i], proxies) || i == 0 {
+			return hops[i]
+		}
+	}
+	return remote
+}
+
+// trustedProxies retourne les réseaux de confiance, analysés une seule fois
+// par configuration.
+func (l *ClientLimiter) trustedProxies(trusted []string) []*net.IPNet {
+	l.proxiesMu.Lock()
+	defer l.proxiesMu.Unlock()
+
+	key := strings.Join(trusted, ",")
+	if l.proxies == nil || key != l.proxiesKey {
+		proxies := make([]*net.IPNet, 0, len(trusted))
+		for _, p := range trusted {
+			if n, err := config.ParseIPNet(p); err == nil {
+				proxies = append(proxies, n)
+			}
+		}
+		l.proxies, l.proxiesKey = proxies, key
+	}
+	return l.proxies
+}
+
+func inNets(ip string, nets []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Frontend sert les fichiers du front depuis dir (index.html sur "/"),
+// pour GET et HEAD seulement. Enregistré sans méthode sur "/", il reçoit
+// aussi les autres méthodes des chemins inconnus : elles répondent 404,
+// comme le ferait le routeur.
+func Frontend(dir string) http.Handler {
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// routeMethods sont les méthodes essayées pour construire l'en-tête Allow.
+var routeMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// MethodNotAllowed s'enregistre sans méthode sur pattern (ex. "/api/v1/")
+// pour y rétablir le 405 que masque le "/" sans méthode du front : si une
+// autre route de mux accepte le chemin avec une autre méthode, la réponse
+// est 405 avec Allow, sinon 404.
+func MethodNotAllowed(mux *http.ServeMux, pattern string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var allow []string
+		for _, method := range routeMethods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, p := mux.Handler(probe); p != "" && p != pattern {
+				allow = append(allow, method)
+			}
+		}
+		if len(allow) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	})
+}
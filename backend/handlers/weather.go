@@ -3,74 +3,129 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"weather-app-backend/models"
 	"weather-app-backend/services"
+	"weather-app-backend/utils"
 )
 
-func WeatherHandler(w http.ResponseWriter, r *http.Request) {
-	city := r.URL.Query().Get("city")
-	if city == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(models.ErrorResponse{
-			Error: "Le paramètre 'city' est obligatoire.",
-		})
-		return
-	}
+// WeatherHandler gère GET /api/v1/weather/{location} et l'ancienne route
+// GET /api/weather?city=Paris.
+func WeatherHandler(svc *services.WeatherService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		city := location(r)
+		if city == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Le paramètre 'city' est obligatoire.",
+			})
+			return
+		}
 
-	data, err := services.GetWeatherForCity(r.Context(), city)
-	if err != nil {
-		var werr *services.WeatherError
-		if errors.As(err, &werr) {
-			switch werr.Type {
-			case services.ErrTypeBadRequest:
-				w.WriteHeader(http.StatusBadRequest)
-				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
-					Error: "La ville saisie est invalide ou non supportée par l’API météo.",
-				})
-				return
-			case services.ErrTypeNotFound:
-				w.WriteHeader(http.StatusNotFound)
-				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
-					Error: "Aucune donnée météo trouvée pour cette ville.",
-				})
-				return
-			case services.ErrTypeConfig:
-				w.WriteHeader(http.StatusInternalServerError)
-				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
-					Error: "Erreur de configuration côté serveur (clé API ou URL manquante).",
-				})
-				return
-			case services.ErrTypeUpstream:
-				w.WriteHeader(http.StatusBadGateway)
-				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
-					Error: "L’API météo externe ne répond pas correctement. Réessaie plus tard.",
-				})
-				return
-			case services.ErrTypeDecode:
-				w.WriteHeader(http.StatusInternalServerError)
-				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
-					Error: "Le serveur n’a pas réussi à comprendre la réponse de l’API météo.",
-				})
-				return
-			default:
-				w.WriteHeader(http.StatusInternalServerError)
-				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
-					Error: "Une erreur interne est survenue lors de la récupération de la météo.",
-				})
-				return
-			}
+		data, err := svc.GetWeatherForCity(r.Context(), city)
+		if err != nil {
+			writeWeatherError(w, r, err)
+			return
 		}
 
-		// Si ce n’est pas un WeatherError (cas improbable), fallback générique
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(models.ErrorResponse{
-			Error: "Erreur inattendue côté serveur.",
-		})
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(data) // renvoie *models.Weather complet
+	}
+}
+
+// writeWeatherError répond à une erreur du service par un models.ErrorResponse
+// dont le statut dépend du type de WeatherError ; le message interne de
+// l'erreur n'est jamais renvoyé au client.
+func writeWeatherError(w http.ResponseWriter, r *http.Request, err error) {
+	if writeTimeout(w, r) {
 		return
 	}
+	var werr *services.WeatherError
+	if errors.As(err, &werr) {
+		switch werr.Type {
+		case services.ErrTypeBadRequest:
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "La ville saisie est invalide ou non supportée par l’API météo.",
+			})
+			return
+		case services.ErrTypeNotFound:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Aucune donnée météo trouvée pour cette ville.",
+			})
+			return
+		case services.ErrTypeConfig:
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Erreur de configuration côté serveur (clé API ou URL manquante).",
+			})
+			return
+		case services.ErrTypeUpstream:
+			w.WriteHeader(http.StatusBadGateway)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "L’API météo externe ne répond pas correctement. Réessaie plus tard.",
+			})
+			return
+		case services.ErrTypeQuota:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Le quota de l’API météo est atteint. Réessaie plus tard.",
+			})
+			return
+		case services.ErrTypeRateLimited:
+			w.Header().Set("Retry-After", retryAfter(err))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Trop de demandes vers l’API météo externe. Réessaie dans quelques secondes.",
+			})
+			return
+		case services.ErrTypeAuth:
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Erreur de configuration côté serveur (clé API refusée par l’API météo).",
+			})
+			return
+		case services.ErrTypeDecode:
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Le serveur n’a pas réussi à comprendre la réponse de l’API météo.",
+			})
+			return
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error: "Une erreur interne est survenue lors de la récupération de la météo.",
+			})
+			return
+		}
+	}
+
+	// Si ce n’est pas un WeatherError (cas improbable), fallback générique
+	w.WriteHeader(http.StatusInternalServerError)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error: "Erreur inattendue côté serveur.",
+	})
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(data) // renvoie *models.Weather complet
+// retryAfter retourne la valeur de l'en-tête Retry-After (en secondes,
+// arrondie au supérieur) d'une erreur de limite de débit.
+func retryAfter(err error) string {
+	var rl *utils.RateLimitError
+	if !errors.As(err, &rl) {
+		return "1"
+	}
+	return strconv.Itoa(int(math.Ceil(rl.RetryAfter.Seconds())))
+}
+
+// location retourne le lieu demandé : segment {location} des routes /api/v1,
+// sinon paramètre city des anciennes routes.
+func location(r *http.Request) string {
+	if loc := r.PathValue("location"); loc != "" {
+		return loc
+	}
+	return r.URL.Query().Get("city")
 }
@@ -1,41 +1,307 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 
 	"weather-app-backend/config"
 	"weather-app-backend/handlers"
+	"weather-app-backend/services"
+	"weather-app-backend/utils"
+)
+
+// Codes de sortie.
+const (
+	exitOK      = 0 // arrêt propre
+	exitFailure = 1 // configuration invalide, démarrage ou écoute impossible
+	exitUnclean = 3 // arrêt incomplet (échéance dépassée, cache non vidé) ; 2 est pris par flag
 )
 
+// Les routes sans version (/api/weather…) sont des alias de /api/v1,
+// dépréciés depuis legacyDeprecated et retirés à legacySunset.
+var (
+	legacyDeprecated = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	legacySunset     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// envFiles collecte les -env-file répétés.
+type envFiles []string
+
+func (f *envFiles) String() string     { return strings.Join(*f, ",") }
+func (f *envFiles) Set(v string) error { *f = append(*f, v); return nil }
+
 func main() {
-	// Charger la config (.env)
-	if err := config.Load(); err != nil {
-		log.Println("warning: could not load config:", err)
+	os.Exit(run())
+}
+
+// run démarre le serveur et retourne le code de sortie.
+func run() int {
+	// aucun secret (clé API, jeton) ne doit apparaître dans les journaux
+	output := utils.NewRedactingWriter(os.Stderr)
+	log.SetOutput(output)
+
+	// journal de démarrage, remplacé une fois la configuration chargée
+	level := new(slog.LevelVar)
+	logger := utils.NewLogger(output, "text", level)
+
+	var extraEnv envFiles
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "fichier de configuration YAML ou TOML (optionnel)")
+	flag.Var(&extraEnv, "env-file", "fichier .env (répétable, le dernier l'emporte) ; défaut : config/.env puis config/.env.$APP_ENV")
+	printConfig := flag.Bool("print-config", false, "affiche la configuration effective (secrets masqués) et sa provenance, puis quitte")
+	flag.Parse()
+
+	opts := config.Options{File: *configFile, EnvFiles: extraEnv}
+	if len(opts.EnvFiles) == 0 {
+		opts.EnvFiles = config.DefaultEnvFiles(logger)
 	}
 
+	// Configuration typée : défauts < fichier < .env < environnement
+	cfg, sources, err := config.Load(opts)
+	if *printConfig {
+		if cfg != nil {
+			_ = config.WriteEffective(os.Stdout, cfg, sources)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	if err != nil {
+		logger.Error("invalid configuration", "error", err)
+		return exitFailure
+	}
+
+	// logging.format est fixé au démarrage ; logging.level suit les rechargements
+	level.Set(utils.ParseLevel(cfg.Logging.Level))
+	logger = utils.NewLogger(output, cfg.Logging.Format, level)
+	slog.SetDefault(logger)
+
+	// Traces (tracing.exporter), lues au démarrage
+	tracer, shutdownTracing, err := newTracerProvider(cfg.Tracing, logger.With("component", "tracing"))
+	if err != nil {
+		logger.Error("cannot start tracing", "error", err)
+		return exitFailure
+	}
+
+	svc, err := services.NewWeatherService(cfg, logger.With("component", "weather"))
+	if err != nil {
+		logger.Error("cannot start the weather service", "error", err)
+		return exitFailure
+	}
+
+	// SIGINT/SIGTERM lancent l'arrêt ; un second signal tue le processus
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Rechargement à chaud : modification des fichiers ou SIGHUP
+	apply := func(next *config.Config) error {
+		if err := svc.Reload(next); err != nil {
+			return err
+		}
+		level.Set(utils.ParseLevel(next.Logging.Level))
+		return nil
+	}
+	reloader := config.NewReloader(opts, cfg, apply, logger.With("component", "config"))
+	reloaderDone := make(chan struct{})
+	go func() {
+		defer close(reloaderDone)
+		reloader.Run(ctx, config.DefaultWatchInterval)
+	}()
+
+	// Limite de débit par client (rate_limit) sur les routes qui appellent les fournisseurs
+	limiter := handlers.NewClientLimiter(svc)
+
+	// Spans et mesures par route, exposées sur /metrics
+	metrics := handlers.NewHTTPMetrics()
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/health", handlers.HealthHandler)
-	mux.HandleFunc("/api/weather", handlers.WeatherHandler)
-	mux.HandleFunc("/api/alerts", handlers.AlertsHandler)
+	route := func(pattern string, h http.Handler) {
+		// les mesures et les spans portent le chemin déclaré, sans la méthode
+		_, path, found := strings.Cut(pattern, " ")
+		if !found {
+			path = pattern
+		}
+		mux.Handle(pattern, handlers.Chain(h, metrics.Instrument(path), handlers.Trace(tracer, path)))
+	}
 
-	// Page d'accueil + assets front
-	// On part du dossier backend et on remonte vers ../frontend
-	frontendDir := filepath.Join("..", "frontend")
-	fileServer := http.FileServer(http.Dir(frontendDir))
+	// /api/v1 : méthode et chemin dans le motif, 405 (avec Allow) sur une
+	// autre méthode, 404 sur un chemin inconnu. Les échéances et les limites
+	// par client restent celles des routes /api/weather et /api/alerts,
+	// partagées avec les alias.
+	weather := handlers.Timeout(svc, "/api/weather")(limiter.Limit("/api/weather", handlers.WeatherHandler(svc)))
+	alerts := handlers.Timeout(svc, "/api/alerts")(limiter.Limit("/api/alerts", handlers.AlertsHandler(svc)))
+	route("GET /api/v1/health", handlers.HealthHandler(svc))
+	route("GET /api/v1/health/live", handlers.LiveHandler())
+	route("GET /api/v1/health/ready", handlers.ReadyHandler(svc))
+	route("GET /api/v1/weather/{location}", weather)
+	route("GET /api/v1/alerts", alerts)
+	route("GET /api/v1/alerts/{location}", alerts)
+	route("/api/v1/", handlers.MethodNotAllowed(mux, "/api/v1/"))
 
-	// Quand on va sur "/", on sert index.html du frontend
-	mux.Handle("/", fileServer)
+	// Anciennes routes, sans méthode comme avant /api/v1 : mêmes réponses,
+	// plus les en-têtes Deprecation, Sunset et Link vers la route /api/v1
+	legacy := func(successor func(*http.Request) string) handlers.Middleware {
+		return handlers.Deprecated(legacyDeprecated, legacySunset, successor)
+	}
+	static := func(path string) func(*http.Request) string {
+		return func(*http.Request) string { return path }
+	}
+	byCity := func(base string, optional bool) func(*http.Request) string {
+		return func(r *http.Request) string {
+			if city := r.URL.Query().Get("city"); city != "" {
+				return base + "/" + url.PathEscape(city)
+			}
+			if optional {
+				return base
+			}
+			return ""
+		}
+	}
+	route("/api/health", legacy(static("/api/v1/health"))(handlers.HealthHandler(svc)))
+	route("/api/health/live", legacy(static("/api/v1/health/live"))(handlers.LiveHandler()))
+	route("/api/health/ready", legacy(static("/api/v1/health/ready"))(handlers.ReadyHandler(svc)))
+	route("/api/weather", legacy(byCity("/api/v1/weather", false))(weather))
+	route("/api/alerts", legacy(byCity("/api/v1/alerts", true))(alerts))
+
+	mux.Handle("GET /metrics", handlers.MetricsHandler(svc, metrics))
+
+	// Page d'accueil + assets front (par défaut ../frontend depuis backend/).
+	// Quand on va sur "/", on sert index.html du frontend ; sans méthode dans
+	// le motif (il chevaucherait les anciennes routes), les autres méthodes
+	// sont refusées par le handler (404).
+	route("/", handlers.Frontend(cfg.Server.FrontendDir))
+
+	// identifiant de requête, journal d'accès puis récupération des paniques
+	handler := handlers.Chain(mux, handlers.RequestID, handlers.AccessLog(logger), handlers.Recover)
+	srv := newServer(cfg.Server, handler, logger)
+	servers := []*http.Server{srv}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		logger.Info("server listening", "url", "http://localhost:"+strconv.Itoa(cfg.Server.Port))
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	// Administration (pprof, build, configuration, cache, fournisseurs, clés)
+	// sur un port séparé, protégée par admin.token
+	if cfg.Admin.Addr != "" {
+		admin := newAdminServer(cfg, handlers.Chain(handlers.AdminHandler(svc), handlers.RequestID, handlers.AccessLog(logger), handlers.Recover), logger)
+		servers = append(servers, admin)
+		go func() {
+			logger.Info("admin server listening", "addr", cfg.Admin.Addr, "enabled", cfg.Admin.Token != "")
+			serveErr <- admin.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		logger.Error("server stopped", "error", err)
+		stop()
+		<-reloaderDone
+		_ = shutdownTracing(context.Background())
+		return exitFailure
+	case <-ctx.Done():
+	}
+	stop()
+	return shutdown(servers, svc, shutdownTracing, reloaderDone, logger)
+}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// newTracerProvider crée le fournisseur de traceurs de tracing.exporter et
+// la fonction qui envoie les derniers spans à l'arrêt ; pour none, le
+// fournisseur est nil (aucun span).
+func newTracerProvider(cfg config.TracingConfig, logger *slog.Logger) (trace.TracerProvider, func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case config.TracingStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.TracingOTLP:
+		exporter, err = otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	default:
+		return nil, func(context.Context) error { return nil }, nil
 	}
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Info("tracing enabled", "exporter", cfg.Exporter, "sample_ratio", cfg.SampleRatio)
+	tp := utils.NewTracerProvider(exporter, cfg.ServiceName, cfg.SampleRatio, logger)
+	return tp, tp.Shutdown, nil
+}
+
+// newServer configure http.Server selon server.* (délais contre les
+// connexions lentes, taille des en-têtes).
+func newServer(cfg config.ServerConfig, handler http.Handler, logger *slog.Logger) *http.Server {
+	return &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.With("component", "http").Handler(), slog.LevelWarn),
+	}
+}
+
+// newAdminServer configure le serveur d'administration : pas d'échéance
+// d'écriture, pour les profils et traces pprof (paramètre seconds).
+func newAdminServer(cfg *config.Config, handler http.Handler, logger *slog.Logger) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Admin.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.With("component", "admin").Handler(), slog.LevelWarn),
+	}
+}
+
+// shutdown arrête les serveurs dans l'échéance server.shutdown_timeout :
+// plus de nouvelles connexions, fin des requêtes en cours, arrêt de la
+// surveillance de la configuration, du service (tâches de fond, vidage du
+// cache) puis envoi des derniers spans. Il retourne exitUnclean si une
+// étape n'a pas abouti.
+func shutdown(servers []*http.Server, svc *services.WeatherService, shutdownTracing func(context.Context) error, reloaderDone <-chan struct{}, logger *slog.Logger) int {
+	timeout := svc.Config().Server.ShutdownTimeout
+	logger.Info("shutting down", "timeout", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	log.Println("Server listening on http://localhost:" + port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+	code := exitOK
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Error("in-flight requests did not finish in time, closing connections", "addr", srv.Addr, "error", err)
+			_ = srv.Close()
+			code = exitUnclean
+		}
+	}
+	<-reloaderDone
+	if err := svc.Shutdown(ctx); err != nil {
+		logger.Error("service shutdown incomplete", "error", err)
+		code = exitUnclean
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("pending spans not exported", "error", err)
+		code = exitUnclean
+	}
+	if code == exitOK {
+		logger.Info("shutdown complete")
 	}
+	return code
 }
@@ -8,6 +8,15 @@ type Weather struct {
 	Latitude  float64 `json:"lat,omitempty"`
 	Longitude float64 `json:"lon,omitempty"`
 
+	// Fournisseur ayant servi la réponse ("weatherapi", "openmeteo", ...)
+	Provider string `json:"provider,omitempty"`
+
+	// Cache : réponse servie depuis le cache, son ancienneté, et si elle est
+	// périmée (revalidation en cours ou fournisseur indisponible)
+	Cached          bool `json:"cached"`
+	CacheAgeSeconds int  `json:"cache_age_seconds,omitempty"`
+	Stale           bool `json:"stale,omitempty"`
+
 	// Conditions actuelles
 	Temperature      float64 `json:"temperature"`        // temp_c
 	FeelsLike        float64 `json:"feels_like"`         // feelslike_c
@@ -50,6 +59,12 @@ type ForecastDay struct {
 	Sunrise      string  `json:"sunrise"`
 	Sunset       string  `json:"sunset"`
 	MoonPhase    string  `json:"moon_phase"`
+
+	// Mode ensemble : désaccord entre fournisseurs ; Confidence n'est
+	// renseignée qu'en mode ensemble, où 0 est une valeur significative
+	Spread     float64  `json:"spread,omitempty"`     // écart-type des max (°C)
+	Confidence *float64 `json:"confidence,omitempty"` // 0 (sources divergentes) à 1 (accord de tous les fournisseurs)
+	Sources    int      `json:"sources,omitempty"`    // nombre de fournisseurs fusionnés
 }
 
 // ForecastHour représente la prévision pour une heure.
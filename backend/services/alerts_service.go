@@ -2,11 +2,9 @@ package services
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
-	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"weather-app-backend/utils"
 )
@@ -22,66 +20,20 @@ type WeatherAlert struct {
 }
 
 // GetGlobalWeatherAlerts récupère les alertes météo pour une ville (ou zone) donnée.
-func GetGlobalWeatherAlerts(ctx context.Context, q string) ([]WeatherAlert, error) {
-	apiKey := os.Getenv("WEATHER_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("weather API key missing (WEATHER_API_KEY)")
-	}
-
-	forecastURL := "http://api.weatherapi.com/v1/forecast.json"
-
-	u, err := url.Parse(forecastURL)
-	if err != nil {
-		return nil, fmt.Errorf("invalid forecast URL: %w", err)
-	}
-
-	qp := u.Query()
-	qp.Set("key", apiKey)
-	qp.Set("q", q)      // ville / coord / code pays, etc.
-	qp.Set("days", "1") // un jour suffit pour les alertes
-	qp.Set("alerts", "yes")
-	u.RawQuery = qp.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+func (s *WeatherService) GetGlobalWeatherAlerts(ctx context.Context, q string) (_ []WeatherAlert, err error) {
+	start := time.Now()
+	ctx, span := utils.StartSpan(ctx, "WeatherService.GetGlobalWeatherAlerts", attribute.String("city", q))
+	defer func() { endSpan(span, err) }()
+	logger := s.logger.With("city", q)
+	ctx = utils.WithLogger(ctx, logger)
+
+	p := s.state.Load().provider
+	alerts, err := p.Alerts(ctx, q)
 	if err != nil {
-		return nil, fmt.Errorf("creating forecast request failed: %w", err)
-	}
-
-	resp, err := utils.HTTPClient().Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("calling forecast API failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("forecast API returned status %d", resp.StatusCode)
-	}
-
-	var raw struct {
-		Alerts struct {
-			Alert []struct {
-				Headline string `json:"headline"`
-				Severity string `json:"severity"`
-				Areas    string `json:"areas"`
-				Event    string `json:"event"`
-				Desc     string `json:"desc"`
-			} `json:"alert"`
-		} `json:"alerts"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return nil, fmt.Errorf("decoding forecast API response failed: %w", err)
-	}
-
-	alerts := make([]WeatherAlert, 0, len(raw.Alerts.Alert))
-	for _, a := range raw.Alerts.Alert {
-		alerts = append(alerts, WeatherAlert{
-			Headline: a.Headline,
-			Severity: a.Severity,
-			Areas:    a.Areas,
-			Event:    a.Event,
-			Desc:     a.Desc,
-		})
+		s.errCounts.add(err)
+		logger.ErrorContext(ctx, "alerts request failed", "provider", p.Name(), "error", err, "latency", time.Since(start))
+		return nil, err
 	}
+	logger.InfoContext(ctx, "alerts served", "provider", p.Name(), "alerts", len(alerts), "latency", time.Since(start))
 	return alerts, nil
 }
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// backgroundTasks suit les travaux lancés hors requête (rafraîchissements
+// du cache) pour que l'arrêt puisse les attendre puis les annuler.
+type backgroundTasks struct {
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+
+	stop   context.Context // annulé quand l'attente de l'arrêt a expiré
+	cancel context.CancelFunc
+}
+
+func newBackgroundTasks() *backgroundTasks {
+	stop, cancel := context.WithCancel(context.Background())
+	return &backgroundTasks{stop: stop, cancel: cancel}
+}
+
+// goDetached lance fn dans une goroutine, avec un contexte qui garde les
+// valeurs de ctx (journal, identifiant de requête) sans son annulation.
+// Il retourne false, sans rien lancer, après close. Sur un suivi nil, la
+// tâche est lancée sans être suivie.
+func (b *backgroundTasks) goDetached(ctx context.Context, fn func(context.Context)) bool {
+	ctx = context.WithoutCancel(ctx)
+	if b == nil {
+		go fn(ctx)
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ctx, cancel := context.WithCancel(ctx)
+		defer context.AfterFunc(b.stop, cancel)()
+		defer cancel()
+		fn(ctx)
+	}()
+	return true
+}
+
+// close refuse les nouvelles tâches et attend celles en cours jusqu'à
+// l'échéance de ctx ; au-delà, elles sont annulées et close retourne
+// l'erreur de ctx une fois qu'elles ont rendu la main.
+func (b *backgroundTasks) close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry est une valeur sérialisée (JSON) avec sa date d'expiration.
+// Stocker du JSON plutôt qu'un pointeur évite que l'appelant modifie
+// l'entrée partagée. Après ExpiresAt l'entrée est périmée mais reste
+// disponible jusqu'à StaleUntil (revalidation, panne du fournisseur).
+type CacheEntry struct {
+	Data       []byte    `json:"data"`
+	StoredAt   time.Time `json:"stored_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	StaleUntil time.Time `json:"stale_until"`
+}
+
+// Age retourne l'ancienneté de l'entrée.
+func (e CacheEntry) Age(now time.Time) time.Duration {
+	return now.Sub(e.StoredAt)
+}
+
+// Fresh indique si l'entrée n'a pas encore expiré.
+func (e CacheEntry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// retainedUntil retourne la date à partir de laquelle l'entrée est supprimée.
+func (e CacheEntry) retainedUntil() time.Time {
+	if e.StaleUntil.After(e.ExpiresAt) {
+		return e.StaleUntil
+	}
+	return e.ExpiresAt
+}
+
+// CacheStats sont les compteurs exposés d'un cache.
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	StaleHits uint64 `json:"stale_hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Capacity  int    `json:"capacity,omitempty"`  // entrées max (mémoire)
+	Bytes     int64  `json:"bytes,omitempty"`     // taille occupée (disque)
+	MaxBytes  int64  `json:"max_bytes,omitempty"` // taille max (disque)
+}
+
+// HitRatio retourne (hits + stale_hits) / total des lectures.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.StaleHits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.StaleHits) / float64(total)
+}
+
+// Cache est un stockage clé → entrée, avec expiration.
+type Cache interface {
+	// Get retourne l'entrée si elle existe et est encore conservée (fraîche
+	// ou périmée avant StaleUntil) ; l'appelant vérifie Fresh.
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+	Delete(key string)
+	Stats() CacheStats
+}
+
+// Flusher est implémenté par les caches qui gardent en mémoire un état à
+// écrire avant l'arrêt (DiskCache, TieredCache).
+type Flusher interface {
+	Flush() error
+}
+
+// Compacter est implémenté par les caches dont les entrées périmées ne
+// disparaissent pas d'elles-mêmes (DiskCache, TieredCache) : le service
+// appelle Compact périodiquement.
+type Compacter interface {
+	Compact()
+}
+
+// CacheItem décrit une entrée du cache, sans ses données.
+type CacheItem struct {
+	Key           string    `json:"key"`
+	Bytes         int64     `json:"bytes"`
+	Fresh         bool      `json:"fresh"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	RetainedUntil time.Time `json:"retained_until"` // fin de la conservation (périmée)
+}
+
+// Lister est implémenté par les caches capables d'énumérer leurs entrées
+// (administration) ; la lecture ne compte ni comme accès ni dans les
+// statistiques.
+type Lister interface {
+	Items() []CacheItem
+}
+
+// MemoryCache est un cache mémoire borné avec éviction LRU.
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // front = plus récemment utilisé
+	stats    CacheStats
+	now      func() time.Time
+}
+
+type memoryItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache crée un cache LRU de `capacity` entrées au maximum.
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (c *MemoryCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return CacheEntry{}, false
+	}
+	item := el.Value.(*memoryItem)
+	now := c.now()
+	if !now.Before(item.entry.retainedUntil()) {
+		c.removeElement(el)
+		c.stats.Misses++
+		return CacheEntry{}, false
+	}
+	c.order.MoveToFront(el)
+	if item.entry.Fresh(now) {
+		c.stats.Hits++
+	} else {
+		c.stats.StaleHits++
+	}
+	return item.entry, true
+}
+
+func (c *MemoryCache) Set(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*memoryItem).entry = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&memoryItem{key: key, entry: entry})
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+func (c *MemoryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.order.Len()
+	s.Capacity = c.capacity
+	return s
+}
+
+// Items retourne les entrées triées par clé.
+func (c *MemoryCache) Items() []CacheItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	items := make([]CacheItem, 0, len(c.items))
+	for key, el := range c.items {
+		e := el.Value.(*memoryItem).entry
+		items = append(items, CacheItem{
+			Key:           key,
+			Bytes:         int64(len(e.Data)),
+			Fresh:         e.Fresh(now),
+			ExpiresAt:     e.ExpiresAt,
+			RetainedUntil: e.retainedUntil(),
+		})
+	}
+	sortItems(items)
+	return items
+}
+
+func sortItems(items []CacheItem) {
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+}
+
+// removeElement retire un élément ; mu doit être tenu.
+func (c *MemoryCache) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*memoryItem).key)
+}
+
+// accentFolder retire les accents les plus courants (latin).
+var accentFolder = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"ç", "c",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u",
+	"ý", "y", "ÿ", "y",
+	"œ", "oe", "æ", "ae", "ß", "ss",
+)
+
+// NormalizeLocation produit la clé de cache d'un lieu : minuscules, sans
+// accents, espaces compactés ; "48.8534, 2.3488" devient "48.85,2.35".
+func NormalizeLocation(location string) string {
+	if lat, lon, ok := parseLatLon(location); ok {
+		return coordKey(lat, lon)
+	}
+	s := accentFolder.Replace(strings.ToLower(location))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// coordKey arrondit des coordonnées à ~1 km pour servir de clé.
+func coordKey(lat, lon float64) string {
+	return fmt.Sprintf("%.2f,%.2f", lat, lon)
+}
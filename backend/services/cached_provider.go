@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"weather-app-backend/config"
+	"weather-app-backend/models"
+	"weather-app-backend/utils"
+)
+
+// revalidateTimeout borne un rafraîchissement en arrière-plan.
+const revalidateTimeout = 15 * time.Second
+
+// CacheTTLs définit la durée de vie par type de donnée, et les fenêtres
+// pendant lesquelles une entrée expirée peut encore être servie.
+type CacheTTLs struct {
+	Current  time.Duration
+	Forecast time.Duration
+	Alerts   time.Duration
+
+	// StaleWhileRevalidate : après expiration, l'entrée est servie
+	// immédiatement et rafraîchie en arrière-plan.
+	StaleWhileRevalidate time.Duration
+	// StaleIfError : après expiration, l'entrée est servie si le
+	// fournisseur est en panne.
+	StaleIfError time.Duration
+}
+
+// CachedProvider met en cache les réponses d'un autre WeatherProvider.
+type CachedProvider struct {
+	inner WeatherProvider
+	cache Cache
+	ttls  CacheTTLs
+	now   func() time.Time
+
+	// revalidating évite de lancer plusieurs rafraîchissements pour une même clé.
+	revalidating sync.Map
+	// tasks suit les rafraîchissements pour l'arrêt du service (nil = non suivis).
+	tasks *backgroundTasks
+}
+
+// NewCachedProvider enveloppe inner avec le cache donné.
+func NewCachedProvider(inner WeatherProvider, cache Cache, ttls CacheTTLs) *CachedProvider {
+	return &CachedProvider{inner: inner, cache: cache, ttls: ttls, now: time.Now}
+}
+
+func (c *CachedProvider) Name() string { return c.inner.Name() }
+
+func (c *CachedProvider) Current(ctx context.Context, location string) (*models.Weather, error) {
+	return c.weather(ctx, "current", location, c.ttls.Current, func(ctx context.Context) (*models.Weather, error) {
+		return c.inner.Current(ctx, location)
+	})
+}
+
+func (c *CachedProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	kind := "forecast:" + strconv.Itoa(days)
+	return c.weather(ctx, kind, location, c.ttls.Forecast, func(ctx context.Context) (*models.Weather, error) {
+		return c.inner.Forecast(ctx, location, days)
+	})
+}
+
+func (c *CachedProvider) Alerts(ctx context.Context, location string) ([]WeatherAlert, error) {
+	key := c.key("alerts", NormalizeLocation(location))
+	alerts, _, err := cachedLookup(ctx, c, key, c.ttls.Alerts, func(ctx context.Context) ([]WeatherAlert, error) {
+		return c.inner.Alerts(ctx, location)
+	}, nil)
+	return alerts, err
+}
+
+// weather sert depuis le cache ou appelle fetch puis mémorise le résultat,
+// aussi sous la clé des coordonnées résolues ("paris" et "48.85,2.35").
+func (c *CachedProvider) weather(ctx context.Context, kind, location string, ttl time.Duration, fetch func(context.Context) (*models.Weather, error)) (*models.Weather, error) {
+	key := c.key(kind, NormalizeLocation(location))
+	aliases := func(w *models.Weather) []string {
+		if w.Latitude == 0 && w.Longitude == 0 {
+			return nil
+		}
+		return []string{c.key(kind, coordKey(w.Latitude, w.Longitude))}
+	}
+
+	w, status, err := cachedLookup(ctx, c, key, ttl, fetch, aliases)
+	if err != nil {
+		return nil, err
+	}
+	w.Cached = status.hit
+	w.Stale = status.stale
+	w.CacheAgeSeconds = int(status.age.Seconds())
+	return w, nil
+}
+
+// cacheStatus décrit comment une valeur a été obtenue.
+type cacheStatus struct {
+	hit   bool
+	stale bool
+	age   time.Duration
+}
+
+// cachedLookup applique la politique de cache à une valeur JSON :
+//   - entrée fraîche : servie telle quelle ;
+//   - expirée depuis moins de StaleWhileRevalidate : servie, et rafraîchie
+//     en arrière-plan ;
+//   - sinon appel au fournisseur ; en cas de panne, l'entrée périmée est
+//     servie tant qu'elle a moins de StaleIfError.
+func cachedLookup[T any](ctx context.Context, c *CachedProvider, key string, ttl time.Duration, fetch func(context.Context) (T, error), aliases func(T) []string) (T, cacheStatus, error) {
+	var (
+		zero     T
+		stale    *T
+		staleAge time.Duration
+	)
+
+	if entry, ok := c.cache.Get(key); ok {
+		var v T
+		if err := json.Unmarshal(entry.Data, &v); err != nil {
+			c.cache.Delete(key)
+		} else {
+			now := c.now()
+			age := entry.Age(now)
+			if entry.Fresh(now) {
+				utils.LoggerFrom(ctx).DebugContext(ctx, "cache hit", "key", key, "cache_hit", true, "age", age.Round(time.Second))
+				return v, cacheStatus{hit: true, age: age}, nil
+			}
+			if now.Sub(entry.ExpiresAt) < c.ttls.StaleWhileRevalidate {
+				utils.LoggerFrom(ctx).DebugContext(ctx, "stale cache hit, revalidating", "key", key, "cache_hit", true, "stale", true, "age", age.Round(time.Second))
+				revalidate(ctx, c, key, ttl, fetch, aliases)
+				return v, cacheStatus{hit: true, stale: true, age: age}, nil
+			}
+			stale, staleAge = &v, age
+		}
+	}
+
+	v, err := fetch(ctx)
+	if err != nil {
+		if stale != nil && isFallbackError(err) {
+			utils.LoggerFrom(ctx).WarnContext(ctx, "serving stale cache after upstream error", "key", key, "cache_hit", true, "stale", true, "age", staleAge.Round(time.Second), "error", err)
+			return *stale, cacheStatus{hit: true, stale: true, age: staleAge}, nil
+		}
+		return zero, cacheStatus{}, err
+	}
+
+	utils.LoggerFrom(ctx).DebugContext(ctx, "cache miss", "key", key, "cache_hit", false)
+	storeLookup(ctx, c, key, v, ttl, aliases)
+	return v, cacheStatus{}, nil
+}
+
+// revalidate rafraîchit une entrée en arrière-plan, indépendamment de la
+// requête qui l'a déclenchée ; rien n'est lancé une fois l'arrêt commencé.
+func revalidate[T any](ctx context.Context, c *CachedProvider, key string, ttl time.Duration, fetch func(context.Context) (T, error), aliases func(T) []string) {
+	if _, busy := c.revalidating.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+	started := c.tasks.goDetached(ctx, func(ctx context.Context) {
+		defer c.revalidating.Delete(key)
+
+		bgCtx, cancel := context.WithTimeout(ctx, revalidateTimeout)
+		defer cancel()
+
+		v, err := fetch(bgCtx)
+		if err != nil {
+			utils.LoggerFrom(bgCtx).WarnContext(bgCtx, "background revalidation failed", "key", key, "error", err)
+			return
+		}
+		storeLookup(bgCtx, c, key, v, ttl, aliases)
+		utils.LoggerFrom(bgCtx).DebugContext(bgCtx, "cache revalidated", "key", key)
+	})
+	if !started {
+		c.revalidating.Delete(key)
+	}
+}
+
+// storeLookup enregistre v sous key et ses alias éventuels.
+func storeLookup[T any](ctx context.Context, c *CachedProvider, key string, v T, ttl time.Duration, aliases func(T) []string) {
+	c.store(ctx, key, v, ttl)
+	if aliases == nil {
+		return
+	}
+	for _, alias := range aliases(v) {
+		if alias != key {
+			c.store(ctx, alias, v, ttl)
+		}
+	}
+}
+
+// store sérialise v et l'enregistre pour ttl (+ fenêtres de péremption).
+func (c *CachedProvider) store(ctx context.Context, key string, v any, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		utils.LoggerFrom(ctx).WarnContext(ctx, "cache encode failed", "key", key, "error", err)
+		return
+	}
+	now := c.now()
+	expires := now.Add(ttl)
+	c.cache.Set(key, CacheEntry{
+		Data:       data,
+		StoredAt:   now,
+		ExpiresAt:  expires,
+		StaleUntil: expires.Add(max(c.ttls.StaleWhileRevalidate, c.ttls.StaleIfError)),
+	})
+}
+
+// key construit "type|fournisseur|lieu".
+func (c *CachedProvider) key(kind, location string) string {
+	return strings.Join([]string{kind, c.inner.Name(), location}, "|")
+}
+
+// NewCacheFromConfig crée le cache du service : mémoire seule, ou mémoire +
+// disque si cfg.DiskDir est défini.
+func NewCacheFromConfig(cfg config.CacheConfig, logger *slog.Logger) Cache {
+	logger = utils.OrDiscard(logger)
+	memory := NewMemoryCache(cfg.MaxEntries)
+	if cfg.DiskDir == "" {
+		return memory
+	}
+	disk, err := NewDiskCache(cfg.DiskDir, cfg.DiskMaxBytes, logger)
+	if err != nil {
+		logger.Warn("disk cache disabled", "error", err)
+		return memory
+	}
+	return NewTieredCache(memory, disk)
+}
+
+// CacheTTLsFromConfig extrait les durées de vie de la configuration.
+func CacheTTLsFromConfig(cfg config.CacheConfig) CacheTTLs {
+	return CacheTTLs{
+		Current:              cfg.TTLCurrent,
+		Forecast:             cfg.TTLForecast,
+		Alerts:               cfg.TTLAlerts,
+		StaleWhileRevalidate: cfg.StaleWhileRevalidate,
+		StaleIfError:         cfg.StaleIfError,
+	}
+}
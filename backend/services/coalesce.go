@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"weather-app-backend/models"
+	"weather-app-backend/utils"
+)
+
+// flightCall est un appel amont en cours, partagé par plusieurs demandeurs.
+type flightCall struct {
+	done    chan struct{}
+	val     any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightGroup déduplique les appels concurrents portant la même clé.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flightCall)}
+}
+
+// do exécute fn une seule fois pour tous les appels concurrents de même clé.
+// L'appel partagé ne dépend de l'annulation d'aucun demandeur : il n'est
+// annulé que si tous les demandeurs abandonnent. Un demandeur qui abandonne
+// reçoit ctx.Err() tel quel : ce n'est pas une panne du fournisseur.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, bool, error) {
+	g.mu.Lock()
+	c, shared := g.calls[key]
+	if !shared {
+		fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		go g.run(fetchCtx, key, c, fn)
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, shared, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// plus personne n'attend : on annule, et un nouvel appel repartira de zéro
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, shared, ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, c *flightCall, fn func(context.Context) (any, error)) {
+	defer c.cancel()
+	c.val, c.err = fn(ctx)
+
+	g.mu.Lock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	close(c.done)
+}
+
+// CoalescingProvider fusionne les appels concurrents identiques (même type,
+// même lieu normalisé, mêmes options) en un seul appel au fournisseur.
+type CoalescingProvider struct {
+	inner WeatherProvider
+	group *flightGroup
+}
+
+// NewCoalescingProvider enveloppe inner avec la déduplication des appels en vol.
+func NewCoalescingProvider(inner WeatherProvider) *CoalescingProvider {
+	return &CoalescingProvider{inner: inner, group: newFlightGroup()}
+}
+
+func (c *CoalescingProvider) Name() string { return c.inner.Name() }
+
+func (c *CoalescingProvider) Current(ctx context.Context, location string) (*models.Weather, error) {
+	return c.weather(ctx, c.key("current", location), func(ctx context.Context) (*models.Weather, error) {
+		return c.inner.Current(ctx, location)
+	})
+}
+
+func (c *CoalescingProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	return c.weather(ctx, c.key("forecast:"+strconv.Itoa(days), location), func(ctx context.Context) (*models.Weather, error) {
+		return c.inner.Forecast(ctx, location, days)
+	})
+}
+
+func (c *CoalescingProvider) Alerts(ctx context.Context, location string) ([]WeatherAlert, error) {
+	key := c.key("alerts", location)
+	v, shared, err := c.group.do(ctx, key, func(ctx context.Context) (any, error) {
+		return c.inner.Alerts(ctx, location)
+	})
+	if shared {
+		utils.LoggerFrom(ctx).DebugContext(ctx, "coalesced upstream call", "key", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// copie : chaque demandeur reçoit sa propre slice
+	return append([]WeatherAlert(nil), v.([]WeatherAlert)...), nil
+}
+
+func (c *CoalescingProvider) weather(ctx context.Context, key string, fetch func(context.Context) (*models.Weather, error)) (*models.Weather, error) {
+	v, shared, err := c.group.do(ctx, key, func(ctx context.Context) (any, error) {
+		return fetch(ctx)
+	})
+	if shared {
+		utils.LoggerFrom(ctx).DebugContext(ctx, "coalesced upstream call", "key", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// copie : le service modifie la réponse (alertes dérivées, fournisseur)
+	w := *v.(*models.Weather)
+	return &w, nil
+}
+
+func (c *CoalescingProvider) key(kind, location string) string {
+	return strings.Join([]string{kind, c.inner.Name(), NormalizeLocation(location)}, "|")
+}
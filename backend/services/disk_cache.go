@@ -0,0 +1,342 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"weather-app-backend/utils"
+)
+
+// defaultDiskCacheMaxBytes borne la taille du cache disque (64 Mo).
+const defaultDiskCacheMaxBytes = 64 << 20
+
+// diskCacheExt est l'extension des fichiers d'entrée.
+const diskCacheExt = ".json"
+
+// Noms des fichiers créés par le cache : entrées (diskFileName) et fichiers
+// temporaires (writeFileAtomic). Les autres fichiers du dossier ne sont
+// jamais lus ni supprimés.
+var (
+	diskEntryName = regexp.MustCompile(`^[0-9a-f]{32}\.json$`)
+	diskTempName  = regexp.MustCompile(`^\.tmp-[0-9]+$`)
+)
+
+// DiskCache est un cache persistant : une entrée par fichier JSON dans dir.
+// Il survit aux redémarrages ; la taille totale est bornée par maxBytes
+// (éviction des entrées les moins récemment lues). Le dossier appartient au
+// cache, qui n'y lit et n'y supprime toutefois que ses propres fichiers.
+type DiskCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	index    map[string]*diskItem
+	size     int64
+	stats    CacheStats
+	now      func() time.Time
+	logger   *slog.Logger
+}
+
+// diskItem est l'index mémoire d'un fichier d'entrée.
+type diskItem struct {
+	file          string
+	size          int64
+	lastAccess    time.Time
+	expiresAt     time.Time
+	retainedUntil time.Time
+	touched       bool // lastAccess plus récent que la date du fichier
+}
+
+// diskRecord est le contenu d'un fichier d'entrée.
+type diskRecord struct {
+	Key   string     `json:"key"`
+	Entry CacheEntry `json:"entry"`
+}
+
+// NewDiskCache ouvre (ou crée) un cache disque dans dir, recharge l'index
+// des entrées existantes puis compacte ; logger peut être nil.
+func NewDiskCache(dir string, maxBytes int64, logger *slog.Logger) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache dir %s: %w", dir, err)
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultDiskCacheMaxBytes
+	}
+	c := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		index:    make(map[string]*diskItem),
+		now:      time.Now,
+		logger:   utils.OrDiscard(logger),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	c.Compact()
+	return c, nil
+}
+
+// load reconstruit l'index à partir des fichiers d'entrée ; les entrées
+// illisibles et les fichiers temporaires (écriture interrompue) sont
+// supprimés, les fichiers qui n'appartiennent pas au cache sont ignorés.
+func (c *DiskCache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("reading cache dir %s: %w", c.dir, err)
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		path := filepath.Join(c.dir, f.Name())
+		if diskTempName.MatchString(f.Name()) {
+			_ = os.Remove(path)
+			continue
+		}
+		if !diskEntryName.MatchString(f.Name()) {
+			continue
+		}
+		rec, err := readDiskRecord(path)
+		if err != nil {
+			c.logger.Warn("dropping unreadable disk cache entry", "file", f.Name(), "error", err)
+			_ = os.Remove(path)
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		c.index[rec.Key] = &diskItem{
+			file:          f.Name(),
+			size:          info.Size(),
+			lastAccess:    info.ModTime(),
+			expiresAt:     rec.Entry.ExpiresAt,
+			retainedUntil: rec.Entry.retainedUntil(),
+		}
+		c.size += info.Size()
+	}
+	c.logger.Info("disk cache loaded", "dir", c.dir, "entries", len(c.index), "bytes", c.size)
+	return nil
+}
+
+func (c *DiskCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.index[key]
+	if !ok {
+		c.stats.Misses++
+		return CacheEntry{}, false
+	}
+	now := c.now()
+	if !now.Before(item.retainedUntil) {
+		c.remove(key, item)
+		c.stats.Misses++
+		return CacheEntry{}, false
+	}
+	rec, err := readDiskRecord(filepath.Join(c.dir, item.file))
+	if err != nil || rec.Key != key {
+		c.remove(key, item)
+		c.stats.Misses++
+		return CacheEntry{}, false
+	}
+
+	item.lastAccess, item.touched = now, true
+	if rec.Entry.Fresh(now) {
+		c.stats.Hits++
+	} else {
+		c.stats.StaleHits++
+	}
+	return rec.Entry, true
+}
+
+func (c *DiskCache) Set(key string, entry CacheEntry) {
+	data, err := json.Marshal(diskRecord{Key: key, Entry: entry})
+	if err != nil {
+		c.logger.Warn("encoding disk cache entry failed", "key", key, "error", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := diskFileName(key)
+	if err := writeFileAtomic(filepath.Join(c.dir, name), data); err != nil {
+		c.logger.Warn("writing disk cache entry failed", "key", key, "error", err)
+		return
+	}
+	if old, ok := c.index[key]; ok {
+		c.size -= old.size
+	}
+	c.index[key] = &diskItem{
+		file:          name,
+		size:          int64(len(data)),
+		lastAccess:    c.now(),
+		expiresAt:     entry.ExpiresAt,
+		retainedUntil: entry.retainedUntil(),
+	}
+	c.size += int64(len(data))
+	c.evictOverflow()
+}
+
+func (c *DiskCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, ok := c.index[key]; ok {
+		c.remove(key, item)
+	}
+}
+
+func (c *DiskCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = len(c.index)
+	s.Bytes = c.size
+	s.MaxBytes = c.maxBytes
+	return s
+}
+
+// Items retourne les entrées de l'index, triées par clé.
+func (c *DiskCache) Items() []CacheItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	items := make([]CacheItem, 0, len(c.index))
+	for key, item := range c.index {
+		items = append(items, CacheItem{
+			Key:           key,
+			Bytes:         item.size,
+			Fresh:         now.Before(item.expiresAt),
+			ExpiresAt:     item.expiresAt,
+			RetainedUntil: item.retainedUntil,
+		})
+	}
+	sortItems(items)
+	return items
+}
+
+// Compact supprime les entrées périmées puis applique la taille maximale.
+func (c *DiskCache) Compact() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	removed := 0
+	for key, item := range c.index {
+		if !now.Before(item.retainedUntil) {
+			c.remove(key, item)
+			removed++
+		}
+	}
+	evicted := c.evictOverflow()
+	if removed > 0 || evicted > 0 {
+		c.logger.Info("disk cache compacted", "expired", removed, "evicted", evicted, "bytes", c.size)
+	}
+}
+
+// Flush compacte le cache puis reporte les dernières lectures sur la date
+// des fichiers, pour que l'ordre d'éviction survive au redémarrage.
+func (c *DiskCache) Flush() error {
+	c.Compact()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for _, item := range c.index {
+		if !item.touched {
+			continue
+		}
+		if err := os.Chtimes(filepath.Join(c.dir, item.file), time.Time{}, item.lastAccess); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		item.touched = false
+	}
+	return errors.Join(errs...)
+}
+
+// evictOverflow retire les entrées les moins récemment lues tant que la
+// taille dépasse maxBytes ; mu doit être tenu.
+func (c *DiskCache) evictOverflow() int {
+	if c.size <= c.maxBytes {
+		return 0
+	}
+	keys := make([]string, 0, len(c.index))
+	for key := range c.index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.index[keys[i]].lastAccess.Before(c.index[keys[j]].lastAccess)
+	})
+
+	evicted := 0
+	for _, key := range keys {
+		if c.size <= c.maxBytes {
+			break
+		}
+		c.remove(key, c.index[key])
+		c.stats.Evictions++
+		evicted++
+	}
+	return evicted
+}
+
+// remove supprime une entrée de l'index et du disque ; mu doit être tenu.
+func (c *DiskCache) remove(key string, item *diskItem) {
+	if err := os.Remove(filepath.Join(c.dir, item.file)); err != nil && !os.IsNotExist(err) {
+		c.logger.Warn("removing disk cache entry failed", "key", key, "error", err)
+	}
+	c.size -= item.size
+	delete(c.index, key)
+}
+
+// diskFileName dérive un nom de fichier stable de la clé.
+func diskFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16]) + diskCacheExt
+}
+
+func readDiskRecord(path string) (*diskRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec diskRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// writeFileAtomic écrit dans un fichier temporaire puis renomme, pour ne
+// jamais laisser d'entrée à moitié écrite après un arrêt brutal.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
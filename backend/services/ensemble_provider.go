@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+
+	"weather-app-backend/models"
+	"weather-app-backend/utils"
+)
+
+// Écarts au-delà desquels la confiance d'une journée tombe à 0 ; elle est
+// en outre proportionnelle à la part des fournisseurs qui ont répondu.
+const (
+	ensembleTempTolerance = 5.0  // °C d'écart-type sur la température max
+	ensembleRainTolerance = 40.0 // points d'écart-type sur la probabilité de pluie
+)
+
+// EnsembleProvider interroge tous les fournisseurs en parallèle et fusionne
+// leurs réponses (moyennes pondérées + indice de désaccord par jour).
+type EnsembleProvider struct {
+	providers []WeatherProvider
+	weights   map[string]float64
+	health    *healthTracker
+}
+
+// NewEnsembleProvider crée un fournisseur « consensus ». Un fournisseur absent
+// de weights a un poids de 1.
+func NewEnsembleProvider(weights map[string]float64, providers ...WeatherProvider) *EnsembleProvider {
+	return &EnsembleProvider{providers: providers, weights: weights, health: newHealthTracker(providers)}
+}
+
+// Health retourne l'état de chaque fournisseur (pas de bascule en mode ensemble).
+func (e *EnsembleProvider) Health() ([]ProviderHealth, int) {
+	return e.health.snapshot()
+}
+
+func (e *EnsembleProvider) members() []WeatherProvider { return e.providers }
+
+// Name retourne ex. "ensemble(weatherapi+openmeteo)".
+func (e *EnsembleProvider) Name() string {
+	names := make([]string, 0, len(e.providers))
+	for _, p := range e.providers {
+		names = append(names, p.Name())
+	}
+	return "ensemble(" + strings.Join(names, "+") + ")"
+}
+
+func (e *EnsembleProvider) Current(ctx context.Context, location string) (*models.Weather, error) {
+	return e.weather(ctx, func(p WeatherProvider) (*models.Weather, error) {
+		return p.Current(ctx, location)
+	})
+}
+
+func (e *EnsembleProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	return e.weather(ctx, func(p WeatherProvider) (*models.Weather, error) {
+		return p.Forecast(ctx, location, days)
+	})
+}
+
+// Alerts fusionne les alertes de tous les fournisseurs (sans doublons).
+func (e *EnsembleProvider) Alerts(ctx context.Context, location string) ([]WeatherAlert, error) {
+	results := make([][]WeatherAlert, len(e.providers))
+	errs := make([]error, len(e.providers))
+
+	var wg sync.WaitGroup
+	for i, p := range e.providers {
+		wg.Add(1)
+		go func(i int, p WeatherProvider) {
+			defer wg.Done()
+			results[i], errs[i] = p.Alerts(ctx, location)
+		}(i, p)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	alerts := []WeatherAlert{}
+	ok := false
+	for i, list := range results {
+		if errs[i] != nil {
+			utils.LoggerFrom(ctx).WarnContext(ctx, "ensemble member alerts failed", "provider", e.providers[i].Name(), "error", errs[i])
+			continue
+		}
+		ok = true
+		for _, a := range list {
+			if key := a.Event + "|" + a.Headline; !seen[key] {
+				seen[key] = true
+				alerts = append(alerts, a)
+			}
+		}
+	}
+	if !ok {
+		return nil, firstError(errs)
+	}
+	return alerts, nil
+}
+
+// weightedWeather est une réponse de fournisseur avec son poids.
+type weightedWeather struct {
+	provider string
+	weight   float64
+	weather  *models.Weather
+}
+
+// weather interroge tous les fournisseurs puis fusionne les succès.
+func (e *EnsembleProvider) weather(ctx context.Context, call func(WeatherProvider) (*models.Weather, error)) (*models.Weather, error) {
+	results := make([]*models.Weather, len(e.providers))
+	errs := make([]error, len(e.providers))
+
+	var wg sync.WaitGroup
+	for i, p := range e.providers {
+		wg.Add(1)
+		go func(i int, p WeatherProvider) {
+			defer wg.Done()
+			results[i], errs[i] = call(p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var members []weightedWeather
+	expected := 0 // fournisseurs de poids non nul, qu'ils aient répondu ou non
+	for i, p := range e.providers {
+		if w, ok := e.weights[p.Name()]; !ok || w > 0 {
+			expected++
+		}
+		if errs[i] != nil {
+			if isFallbackError(errs[i]) && ctx.Err() == nil {
+				e.health.recordFailure(p.Name(), errs[i], 0)
+			}
+			utils.LoggerFrom(ctx).WarnContext(ctx, "ensemble member failed", "provider", p.Name(), "error", errs[i])
+			continue
+		}
+		e.health.recordSuccess(p.Name())
+		weight := 1.0
+		if w, ok := e.weights[p.Name()]; ok {
+			weight = w
+		}
+		if weight > 0 {
+			members = append(members, weightedWeather{provider: p.Name(), weight: weight, weather: results[i]})
+		}
+	}
+
+	if len(members) == 0 {
+		if err := firstError(errs); err != nil {
+			return nil, err
+		}
+		// tous les fournisseurs ont répondu mais aucun n'a de poids
+		return nil, newWeatherError(ErrTypeConfig, "aucun fournisseur de poids non nul en mode ensemble", nil)
+	}
+
+	merged := mergeWeather(members, expected)
+	names := make([]string, 0, len(members))
+	for _, m := range members {
+		names = append(names, m.provider)
+	}
+	merged.Provider = "ensemble(" + strings.Join(names, "+") + ")"
+	return merged, nil
+}
+
+// mergeWeather fusionne les réponses : les champs textuels viennent du premier
+// fournisseur (priorité), les valeurs numériques sont moyennées ; expected
+// est le nombre de fournisseurs attendus (confiance).
+func mergeWeather(members []weightedWeather, expected int) *models.Weather {
+	base := *members[0].weather
+	merged := &base
+
+	merged.Temperature = weightedMean(members, func(w *models.Weather) (float64, bool) { return w.Temperature, true })
+	merged.FeelsLike = weightedMean(members, func(w *models.Weather) (float64, bool) { return w.FeelsLike, true })
+	merged.WindKph = weightedMean(members, func(w *models.Weather) (float64, bool) { return w.WindKph, true })
+
+	days := members[0].weather.ForecastDays
+	merged.ForecastDays = make([]models.ForecastDay, len(days))
+	for i, day := range days {
+		merged.ForecastDays[i] = mergeForecastDay(day, members, expected)
+	}
+
+	hours := members[0].weather.Hourly
+	merged.Hourly = make([]models.ForecastHour, len(hours))
+	for i, hour := range hours {
+		merged.Hourly[i] = mergeForecastHour(hour, members)
+	}
+
+	return merged
+}
+
+// mergeForecastDay moyenne une journée sur les fournisseurs ayant la même date.
+func mergeForecastDay(day models.ForecastDay, members []weightedWeather, expected int) models.ForecastDay {
+	byDate := func(w *models.Weather) *models.ForecastDay {
+		for i := range w.ForecastDays {
+			if w.ForecastDays[i].Date == day.Date {
+				return &w.ForecastDays[i]
+			}
+		}
+		return nil
+	}
+	field := func(get func(d *models.ForecastDay) float64) func(*models.Weather) (float64, bool) {
+		return func(w *models.Weather) (float64, bool) {
+			d := byDate(w)
+			if d == nil {
+				return 0, false
+			}
+			return get(d), true
+		}
+	}
+
+	maxTemp := field(func(d *models.ForecastDay) float64 { return d.MaxTemp })
+	rain := field(func(d *models.ForecastDay) float64 { return float64(d.ChanceOfRain) })
+
+	day.MinTemp = weightedMean(members, field(func(d *models.ForecastDay) float64 { return d.MinTemp }))
+	day.MaxTemp = weightedMean(members, maxTemp)
+	day.AvgTemp = weightedMean(members, field(func(d *models.ForecastDay) float64 { return d.AvgTemp }))
+	day.WindMaxKph = weightedMean(members, field(func(d *models.ForecastDay) float64 { return d.WindMaxKph }))
+	day.GustMaxKph = weightedMean(members, field(func(d *models.ForecastDay) float64 { return d.GustMaxKph }))
+	day.ChanceOfRain = int(math.Round(weightedMean(members, rain)))
+	day.ChanceOfSnow = int(math.Round(weightedMean(members, field(func(d *models.ForecastDay) float64 { return float64(d.ChanceOfSnow) }))))
+
+	sources := 0
+	for _, m := range members {
+		if d := byDate(m.weather); d != nil {
+			sources++
+			// Un seul fournisseur annonçant de l'orage suffit pour signaler le risque.
+			day.RiskThunder = day.RiskThunder || d.RiskThunder
+		}
+	}
+
+	tempSpread := weightedStdDev(members, maxTemp)
+	rainSpread := weightedStdDev(members, rain)
+	day.Spread = math.Round(tempSpread*10) / 10
+	c := math.Round(confidence(tempSpread, rainSpread, sources, expected)*100) / 100
+	day.Confidence = &c
+	day.Sources = sources
+	return day
+}
+
+// mergeForecastHour moyenne une heure sur les fournisseurs ayant le même horaire.
+func mergeForecastHour(hour models.ForecastHour, members []weightedWeather) models.ForecastHour {
+	field := func(get func(h *models.ForecastHour) float64) func(*models.Weather) (float64, bool) {
+		return func(w *models.Weather) (float64, bool) {
+			for i := range w.Hourly {
+				if w.Hourly[i].Time == hour.Time {
+					return get(&w.Hourly[i]), true
+				}
+			}
+			return 0, false
+		}
+	}
+
+	hour.Temp = weightedMean(members, field(func(h *models.ForecastHour) float64 { return h.Temp }))
+	hour.WindKph = weightedMean(members, field(func(h *models.ForecastHour) float64 { return h.WindKph }))
+	hour.GustKph = weightedMean(members, field(func(h *models.ForecastHour) float64 { return h.GustKph }))
+	hour.ChanceOfRain = int(math.Round(weightedMean(members, field(func(h *models.ForecastHour) float64 { return float64(h.ChanceOfRain) }))))
+	return hour
+}
+
+// weightedMean calcule la moyenne pondérée des valeurs disponibles.
+func weightedMean(members []weightedWeather, get func(*models.Weather) (float64, bool)) float64 {
+	var sum, total float64
+	for _, m := range members {
+		if v, ok := get(m.weather); ok {
+			sum += v * m.weight
+			total += m.weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// weightedStdDev calcule l'écart-type pondéré des valeurs disponibles.
+func weightedStdDev(members []weightedWeather, get func(*models.Weather) (float64, bool)) float64 {
+	mean := weightedMean(members, get)
+	var sum, total float64
+	for _, m := range members {
+		if v, ok := get(m.weather); ok {
+			sum += m.weight * (v - mean) * (v - mean)
+			total += m.weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / total)
+}
+
+// confidence convertit les écarts température / pluie en indice 0..1,
+// pondéré par la part des fournisseurs attendus qui ont fourni la journée :
+// une source seule n'a pas de contradicteur, son accord ne vaut pas 1.
+func confidence(tempSpread, rainSpread float64, sources, expected int) float64 {
+	if expected < sources {
+		expected = sources
+	}
+	disagreement := (tempSpread/ensembleTempTolerance + rainSpread/ensembleRainTolerance) / 2
+	return math.Max(0, 1-disagreement) * float64(sources) / float64(expected)
+}
+
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"weather-app-backend/config"
+)
+
+// KeyPool répartit les appels WeatherAPI entre plusieurs clés, compte les
+// appels par clé et par période, et écarte une clé refusée (quota ou
+// authentification) jusqu'à la période suivante.
+type KeyPool struct {
+	mu     sync.Mutex
+	keys   []*keyState
+	quota  int
+	period string
+	next   int
+	now    func() time.Time
+}
+
+// keyState est le compteur d'une clé.
+type keyState struct {
+	key         string
+	periodStart time.Time
+	calls       int
+	totalCalls  int
+	blocked     string // raison si la clé est écartée pour la période
+	lastError   string
+}
+
+// KeyUsage est l'utilisation exposée d'une clé (jamais la clé elle-même).
+type KeyUsage struct {
+	ID          string    `json:"id"` // "key-1 (…abcd)"
+	Calls       int       `json:"calls"`
+	Quota       int       `json:"quota,omitempty"`
+	Remaining   *int      `json:"remaining,omitempty"`
+	TotalCalls  int       `json:"total_calls"`
+	PeriodStart time.Time `json:"period_start"`
+	Available   bool      `json:"available"`
+	Blocked     string    `json:"blocked,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Raisons pour lesquelles une clé est écartée.
+const (
+	keyBlockedQuota = "quota"
+	keyBlockedAuth  = "auth"
+)
+
+// NewKeyPool crée un pool ; quota = appels max par clé et par période
+// (0 = illimité), period = config.QuotaMonth, QuotaDay ou QuotaHour.
+func NewKeyPool(keys []string, quota int, period string) *KeyPool {
+	p := &KeyPool{now: time.Now}
+	p.Update(keys, quota, period)
+	return p
+}
+
+// Update remplace la liste des clés (rechargement de configuration) en
+// conservant les compteurs des clés toujours présentes.
+func (p *KeyPool) Update(keys []string, quota int, period string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.quota, p.period, p.next = quota, period, 0
+	existing := make(map[string]*keyState, len(p.keys))
+	for _, k := range p.keys {
+		existing[k.key] = k
+	}
+	p.keys = p.keys[:0:0]
+	for _, key := range keys {
+		st, ok := existing[key]
+		if !ok {
+			st = &keyState{key: key, periodStart: p.periodStart(p.now())}
+		}
+		p.keys = append(p.keys, st)
+	}
+}
+
+// Len retourne le nombre de clés.
+func (p *KeyPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.keys)
+}
+
+// Acquire choisit la prochaine clé disponible (tour de rôle) et compte
+// l'appel. Retourne une erreur de quota si toutes sont épuisées ou refusées.
+func (p *KeyPool) Acquire() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	allAuth := len(p.keys) > 0
+	for i := 0; i < len(p.keys); i++ {
+		st := p.keys[(p.next+i)%len(p.keys)]
+		p.roll(st, now)
+		if st.blocked != keyBlockedAuth {
+			allAuth = false
+		}
+		if !p.available(st) {
+			continue
+		}
+		p.next = (p.next + i + 1) % len(p.keys)
+		st.calls++
+		st.totalCalls++
+		return st.key, nil
+	}
+
+	if allAuth {
+		return "", newWeatherError(ErrTypeAuth, "toutes les clés WeatherAPI ont été refusées", nil)
+	}
+	return "", newWeatherError(ErrTypeQuota, "quota de toutes les clés WeatherAPI atteint pour la période", nil)
+}
+
+// Refund annule l'appel compté par Acquire pour key quand la requête n'a
+// pas quitté le processus (disjoncteur ouvert, limite de débit sortante) :
+// elle n'a rien consommé du quota WeatherAPI.
+func (p *KeyPool) Refund(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, st := range p.keys {
+		if st.key != key {
+			continue
+		}
+		p.roll(st, p.now())
+		if st.calls > 0 {
+			st.calls--
+		}
+		if st.totalCalls > 0 {
+			st.totalCalls--
+		}
+		return
+	}
+}
+
+// Reject écarte une clé jusqu'à la fin de la période (quota dépassé ou
+// clé refusée par WeatherAPI) et retourne son identifiant ("key-2").
+func (p *KeyPool) Reject(key string, err *WeatherError) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, st := range p.keys {
+		if st.key != key {
+			continue
+		}
+		st.blocked = keyBlockedAuth
+		if err.Type == ErrTypeQuota {
+			st.blocked = keyBlockedQuota
+		}
+		st.lastError = err.Message
+		return fmt.Sprintf("key-%d", i+1)
+	}
+	return ""
+}
+
+// Usage retourne l'utilisation de chaque clé.
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	usage := make([]KeyUsage, 0, len(p.keys))
+	for i, st := range p.keys {
+		p.roll(st, now)
+		u := KeyUsage{
+			ID:          fmt.Sprintf("key-%d (…%s)", i+1, keySuffix(st.key)),
+			Calls:       st.calls,
+			Quota:       p.quota,
+			TotalCalls:  st.totalCalls,
+			PeriodStart: st.periodStart,
+			Available:   p.available(st),
+			Blocked:     st.blocked,
+			LastError:   st.lastError,
+		}
+		if p.quota > 0 {
+			remaining := max(p.quota-st.calls, 0)
+			u.Remaining = &remaining
+		}
+		usage = append(usage, u)
+	}
+	return usage
+}
+
+// available indique si la clé peut encore être utilisée ; mu doit être tenu.
+func (p *KeyPool) available(st *keyState) bool {
+	return st.blocked == "" && (p.quota <= 0 || st.calls < p.quota)
+}
+
+// roll remet le compteur à zéro au changement de période ; mu doit être tenu.
+func (p *KeyPool) roll(st *keyState, now time.Time) {
+	if start := p.periodStart(now); start.After(st.periodStart) {
+		st.periodStart = start
+		st.calls = 0
+		st.blocked = ""
+	}
+}
+
+// periodStart retourne le début (UTC) de la période contenant t.
+func (p *KeyPool) periodStart(t time.Time) time.Time {
+	t = t.UTC()
+	switch p.period {
+	case config.QuotaHour:
+		return t.Truncate(time.Hour)
+	case config.QuotaDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// keySuffix retourne les 4 derniers caractères d'une clé, pour l'identifier.
+func keySuffix(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[len(key)-4:]
+}
@@ -0,0 +1,447 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"weather-app-backend/config"
+	"weather-app-backend/models"
+	"weather-app-backend/utils"
+)
+
+// ProviderOpenMeteo identifie le fournisseur Open-Meteo (sans clé API).
+const ProviderOpenMeteo = config.ProviderOpenMeteo
+
+// Variables demandées à l'API forecast.
+const (
+	openMeteoCurrentVars = "temperature_2m,apparent_temperature,relative_humidity_2m,weather_code,cloud_cover,pressure_msl,wind_speed_10m,wind_direction_10m,visibility,uv_index"
+	openMeteoHourlyVars  = "temperature_2m,weather_code,precipitation_probability,wind_speed_10m,wind_gusts_10m,pressure_msl,uv_index"
+	openMeteoDailyVars   = "weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max,wind_speed_10m_max,wind_gusts_10m_max,sunrise,sunset"
+)
+
+// OpenMeteoProvider implémente WeatherProvider sur Open-Meteo
+// (géocodage + forecast + qualité de l'air).
+type OpenMeteoProvider struct {
+	forecastURL   string
+	airQualityURL string
+	geocodingURL  string
+	client        *http.Client
+}
+
+// NewOpenMeteoProvider crée un fournisseur Open-Meteo.
+func NewOpenMeteoProvider(forecastURL, airQualityURL, geocodingURL string, client *http.Client) *OpenMeteoProvider {
+	return &OpenMeteoProvider{
+		forecastURL:   forecastURL,
+		airQualityURL: airQualityURL,
+		geocodingURL:  geocodingURL,
+		client:        client,
+	}
+}
+
+func (p *OpenMeteoProvider) Name() string { return ProviderOpenMeteo }
+
+// probeURLs : géocodage et prévisions, sans lesquels aucune réponse n'est
+// possible (la qualité de l'air est facultative).
+func (p *OpenMeteoProvider) probeURLs() []string { return []string{p.geocodingURL, p.forecastURL} }
+
+// Current retourne les conditions actuelles (sans prévisions).
+func (p *OpenMeteoProvider) Current(ctx context.Context, location string) (*models.Weather, error) {
+	return p.fetch(ctx, location, 1, false)
+}
+
+// Forecast retourne conditions actuelles + prévisions journalières et horaires.
+func (p *OpenMeteoProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	return p.fetch(ctx, location, days, true)
+}
+
+// Alerts : Open-Meteo ne diffuse pas d'alertes officielles.
+func (p *OpenMeteoProvider) Alerts(ctx context.Context, location string) ([]WeatherAlert, error) {
+	if _, err := p.resolve(ctx, location); err != nil {
+		return nil, err
+	}
+	return []WeatherAlert{}, nil
+}
+
+// openMeteoPlace est un lieu résolu (coordonnées + libellés).
+type openMeteoPlace struct {
+	Name    string  `json:"name"`
+	Country string  `json:"country"`
+	Admin1  string  `json:"admin1"`
+	Lat     float64 `json:"latitude"`
+	Lon     float64 `json:"longitude"`
+}
+
+// resolve transforme "Paris" ou "48.85,2.35" en coordonnées.
+func (p *OpenMeteoProvider) resolve(ctx context.Context, location string) (*openMeteoPlace, error) {
+	if lat, lon, ok := parseLatLon(location); ok {
+		return &openMeteoPlace{Name: location, Lat: lat, Lon: lon}, nil
+	}
+
+	params := url.Values{}
+	params.Set("name", location)
+	params.Set("count", "1")
+	params.Set("language", "fr")
+	params.Set("format", "json")
+
+	var raw struct {
+		Results []openMeteoPlace `json:"results"`
+	}
+	if err := p.getJSON(ctx, p.geocodingURL, params, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw.Results) == 0 {
+		return nil, newWeatherError(ErrTypeNotFound, "ville ou ressource météo introuvable", nil)
+	}
+	return &raw.Results[0], nil
+}
+
+// fetch résout le lieu puis interroge forecast (+ qualité de l'air).
+func (p *OpenMeteoProvider) fetch(ctx context.Context, location string, days int, withForecast bool) (*models.Weather, error) {
+	place, err := p.resolve(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("latitude", formatCoord(place.Lat))
+	params.Set("longitude", formatCoord(place.Lon))
+	params.Set("current", openMeteoCurrentVars)
+	params.Set("timezone", "auto")
+	params.Set("forecast_days", strconv.Itoa(days))
+	if withForecast {
+		params.Set("hourly", openMeteoHourlyVars)
+		params.Set("daily", openMeteoDailyVars)
+	}
+
+	var raw openMeteoForecastResponse
+	if err := p.getJSON(ctx, p.forecastURL, params, &raw); err != nil {
+		return nil, err
+	}
+
+	w := buildOpenMeteoModel(place, &raw, withForecast)
+
+	// La qualité de l’air est optionnelle : un échec ne bloque pas la réponse.
+	aqi, err := p.airQuality(ctx, place)
+	if err != nil {
+		utils.LoggerFrom(ctx).WarnContext(ctx, "air quality unavailable", "provider", ProviderOpenMeteo, "error", err)
+	} else {
+		w.AirQualityIndex = aqi
+	}
+
+	return w, nil
+}
+
+// airQuality retourne l’indice US EPA (1 à 6) comme WeatherAPI.
+func (p *OpenMeteoProvider) airQuality(ctx context.Context, place *openMeteoPlace) (float64, error) {
+	params := url.Values{}
+	params.Set("latitude", formatCoord(place.Lat))
+	params.Set("longitude", formatCoord(place.Lon))
+	params.Set("current", "us_aqi")
+
+	var raw struct {
+		Current struct {
+			USAQI float64 `json:"us_aqi"`
+		} `json:"current"`
+	}
+	if err := p.getJSON(ctx, p.airQualityURL, params, &raw); err != nil {
+		return 0, err
+	}
+	return usAQIToEPAIndex(raw.Current.USAQI), nil
+}
+
+// getJSON exécute un GET et décode le JSON dans out.
+func (p *OpenMeteoProvider) getJSON(ctx context.Context, rawURL string, params url.Values, out any) (err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return newWeatherError(ErrTypeConfig, "URL Open-Meteo invalide", err)
+	}
+	u.RawQuery = params.Encode()
+
+	ctx, span := utils.StartSpan(ctx, "openmeteo.get", attribute.String("provider", ProviderOpenMeteo), attribute.String("url.path", u.Path))
+	defer func() { endSpan(span, err) }()
+
+	logger := utils.LoggerFrom(ctx).With("provider", ProviderOpenMeteo)
+	logger.DebugContext(ctx, "calling upstream", "url", utils.Redact(u.String()))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return newWeatherError(ErrTypeUnknown, "impossible de créer la requête HTTP", err)
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.WarnContext(ctx, "upstream call failed", "error", utils.Redact(err.Error()), "latency", time.Since(start))
+		return callError(err)
+	}
+	defer resp.Body.Close()
+
+	logger.InfoContext(ctx, "upstream response", "status", resp.StatusCode, "latency", time.Since(start))
+
+	if werr := handleUpstreamStatus(resp.StatusCode); werr != nil {
+		return werr
+	}
+
+	_, decodeSpan := utils.StartSpan(ctx, "openmeteo.decode")
+	defer decodeSpan.End()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return newWeatherError(ErrTypeDecode, "impossible de décoder la réponse de l’API météo", err)
+	}
+	return nil
+}
+
+// openMeteoForecastResponse décrit la réponse de /v1/forecast.
+type openMeteoForecastResponse struct {
+	Current struct {
+		Temperature   float64 `json:"temperature_2m"`
+		ApparentTemp  float64 `json:"apparent_temperature"`
+		Humidity      int     `json:"relative_humidity_2m"`
+		WeatherCode   int     `json:"weather_code"`
+		CloudCover    int     `json:"cloud_cover"`
+		PressureMsl   float64 `json:"pressure_msl"`
+		WindSpeed     float64 `json:"wind_speed_10m"`
+		WindDirection int     `json:"wind_direction_10m"`
+		VisibilityM   float64 `json:"visibility"`
+		UV            float64 `json:"uv_index"`
+	} `json:"current"`
+	Hourly struct {
+		Time                     []string  `json:"time"`
+		Temperature              []float64 `json:"temperature_2m"`
+		WeatherCode              []int     `json:"weather_code"`
+		PrecipitationProbability []int     `json:"precipitation_probability"`
+		WindSpeed                []float64 `json:"wind_speed_10m"`
+		WindGusts                []float64 `json:"wind_gusts_10m"`
+		PressureMsl              []float64 `json:"pressure_msl"`
+		UV                       []float64 `json:"uv_index"`
+	} `json:"hourly"`
+	Daily struct {
+		Time                        []string  `json:"time"`
+		WeatherCode                 []int     `json:"weather_code"`
+		TemperatureMax              []float64 `json:"temperature_2m_max"`
+		TemperatureMin              []float64 `json:"temperature_2m_min"`
+		PrecipitationProbabilityMax []int     `json:"precipitation_probability_max"`
+		WindSpeedMax                []float64 `json:"wind_speed_10m_max"`
+		WindGustsMax                []float64 `json:"wind_gusts_10m_max"`
+		Sunrise                     []string  `json:"sunrise"`
+		Sunset                      []string  `json:"sunset"`
+	} `json:"daily"`
+}
+
+// buildOpenMeteoModel convertit la réponse Open-Meteo en models.Weather.
+func buildOpenMeteoModel(place *openMeteoPlace, raw *openMeteoForecastResponse, withForecast bool) *models.Weather {
+	cur := raw.Current
+	w := &models.Weather{
+		City:         place.Name,
+		Country:      place.Country,
+		Region:       place.Admin1,
+		Latitude:     place.Lat,
+		Longitude:    place.Lon,
+		Temperature:  cur.Temperature,
+		FeelsLike:    cur.ApparentTemp,
+		Condition:    wmoConditionText(cur.WeatherCode),
+		Humidity:     cur.Humidity,
+		WindKph:      cur.WindSpeed,
+		WindDegree:   cur.WindDirection,
+		WindDir:      compassDirection(cur.WindDirection),
+		PressureMb:   cur.PressureMsl,
+		VisibilityKm: cur.VisibilityM / 1000,
+		UV:           cur.UV,
+		Cloud:        cur.CloudCover,
+	}
+
+	if !withForecast {
+		return w
+	}
+
+	// Prévisions journalières (limitées à 3 jours pour rester lisible)
+	d := raw.Daily
+	for i := range d.Time {
+		if i >= 3 {
+			break
+		}
+		code := intAt(d.WeatherCode, i)
+		rain := intAt(d.PrecipitationProbabilityMax, i)
+		snow := 0
+		if wmoIsSnow(code) {
+			snow = rain
+		}
+		minT, maxT := floatAt(d.TemperatureMin, i), floatAt(d.TemperatureMax, i)
+		w.ForecastDays = append(w.ForecastDays, models.ForecastDay{
+			Date:         d.Time[i],
+			MinTemp:      minT,
+			MaxTemp:      maxT,
+			AvgTemp:      (minT + maxT) / 2, // non fourni par Open-Meteo
+			Condition:    wmoConditionText(code),
+			ChanceOfRain: rain,
+			ChanceOfSnow: snow,
+			WindMaxKph:   floatAt(d.WindSpeedMax, i),
+			GustMaxKph:   floatAt(d.WindGustsMax, i),
+			Sunrise:      clockTime(stringAt(d.Sunrise, i)),
+			Sunset:       clockTime(stringAt(d.Sunset, i)),
+			RiskThunder:  wmoIsThunder(code),
+		})
+	}
+
+	// Prévisions horaires : 24 premières heures
+	h := raw.Hourly
+	for i := range h.Time {
+		if i >= 24 {
+			break
+		}
+		w.Hourly = append(w.Hourly, models.ForecastHour{
+			Time:         strings.Replace(h.Time[i], "T", " ", 1),
+			Temp:         floatAt(h.Temperature, i),
+			Condition:    wmoConditionText(intAt(h.WeatherCode, i)),
+			ChanceOfRain: intAt(h.PrecipitationProbability, i),
+			WindKph:      floatAt(h.WindSpeed, i),
+			GustKph:      floatAt(h.WindGusts, i),
+			PressureMb:   floatAt(h.PressureMsl, i),
+			UV:           floatAt(h.UV, i),
+		})
+	}
+
+	return w
+}
+
+// wmoConditions associe les codes météo WMO (Open-Meteo) à un libellé.
+var wmoConditions = map[int]string{
+	0:  "Ciel dégagé",
+	1:  "Principalement dégagé",
+	2:  "Partiellement nuageux",
+	3:  "Couvert",
+	45: "Brouillard",
+	48: "Brouillard givrant",
+	51: "Bruine légère",
+	53: "Bruine modérée",
+	55: "Bruine dense",
+	56: "Bruine verglaçante légère",
+	57: "Bruine verglaçante dense",
+	61: "Pluie faible",
+	63: "Pluie modérée",
+	65: "Pluie forte",
+	66: "Pluie verglaçante faible",
+	67: "Pluie verglaçante forte",
+	71: "Chute de neige faible",
+	73: "Chute de neige modérée",
+	75: "Chute de neige forte",
+	77: "Grains de neige",
+	80: "Averses de pluie faibles",
+	81: "Averses de pluie modérées",
+	82: "Averses de pluie violentes",
+	85: "Averses de neige faibles",
+	86: "Averses de neige fortes",
+	95: "Orage",
+	96: "Orage avec grêle faible",
+	99: "Orage avec grêle forte",
+}
+
+// wmoConditionText retourne le libellé d’un code WMO.
+func wmoConditionText(code int) string {
+	if text, ok := wmoConditions[code]; ok {
+		return text
+	}
+	return "Inconnu"
+}
+
+// wmoIsThunder est l’équivalent WMO de isThunderRisk (codes 95, 96, 99).
+func wmoIsThunder(code int) bool {
+	switch code {
+	case 95, 96, 99:
+		return true
+	default:
+		return false
+	}
+}
+
+// wmoIsSnow indique si le code WMO correspond à de la neige.
+func wmoIsSnow(code int) bool {
+	switch code {
+	case 71, 73, 75, 77, 85, 86:
+		return true
+	default:
+		return false
+	}
+}
+
+// usAQIToEPAIndex convertit un US AQI (0-500) en catégorie EPA (1-6).
+func usAQIToEPAIndex(aqi float64) float64 {
+	switch {
+	case aqi <= 0:
+		return 0
+	case aqi <= 50:
+		return 1
+	case aqi <= 100:
+		return 2
+	case aqi <= 150:
+		return 3
+	case aqi <= 200:
+		return 4
+	case aqi <= 300:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// compassDirection convertit un angle en point cardinal (N, NNE, ...).
+func compassDirection(deg int) string {
+	points := []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+	idx := int(math.Round(float64(((deg%360)+360)%360)/22.5)) % len(points)
+	return points[idx]
+}
+
+// clockTime convertit "2025-06-01T05:50" en "05:50 AM" (format WeatherAPI).
+func clockTime(iso string) string {
+	t, err := time.Parse("2006-01-02T15:04", iso)
+	if err != nil {
+		return iso
+	}
+	return t.Format("03:04 PM")
+}
+
+// parseLatLon reconnaît une saisie "lat,lon".
+func parseLatLon(s string) (float64, float64, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+func floatAt(values []float64, i int) float64 {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
+func intAt(values []int, i int) int {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
+func stringAt(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
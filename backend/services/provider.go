@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"weather-app-backend/config"
+	"weather-app-backend/models"
+	"weather-app-backend/utils"
+)
+
+// WeatherProvider abstrait une source de données météo (WeatherAPI, ...).
+// Chaque implémentation renvoie des modèles neutres : les handlers ne
+// dépendent plus du JSON d'un fournisseur particulier.
+type WeatherProvider interface {
+	// Name retourne l'identifiant du fournisseur (ex. "weatherapi").
+	Name() string
+	// Current retourne uniquement les conditions actuelles.
+	Current(ctx context.Context, location string) (*models.Weather, error)
+	// Forecast retourne les conditions actuelles + prévisions sur `days` jours.
+	Forecast(ctx context.Context, location string, days int) (*models.Weather, error)
+	// Alerts retourne les alertes officielles pour la zone.
+	Alerts(ctx context.Context, location string) ([]WeatherAlert, error)
+}
+
+// NewProviderFromConfig instancie les fournisseurs de cfg.Order, combinés
+// selon cfg.Mode : chaîne de secours (défaut) ou ensemble. keys porte les
+// compteurs des clés WeatherAPI d'un rechargement à l'autre (nil = nouveau pool),
+// upstreams les clients HTTP de chaque fournisseur (nil = nouveau registre).
+func NewProviderFromConfig(cfg config.ProvidersConfig, upstreams *utils.Upstreams, keys *KeyPool) (WeatherProvider, error) {
+	if upstreams == nil {
+		upstreams = utils.NewUpstreams()
+	}
+	providers := make([]WeatherProvider, 0, len(cfg.Order))
+	for _, name := range cfg.Order {
+		p, err := newProviderByName(name, cfg, upstreams, keys)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+	if len(providers) == 0 {
+		return nil, newWeatherError(ErrTypeConfig, "aucun fournisseur météo configuré", nil)
+	}
+
+	if cfg.Mode == config.ModeEnsemble {
+		return NewEnsembleProvider(cfg.EnsembleWeights, providers...), nil
+	}
+	return NewProviderChain(cfg.Cooldown, providers...), nil
+}
+
+// newProviderByName instancie un fournisseur à partir de son identifiant.
+func newProviderByName(name string, cfg config.ProvidersConfig, upstreams *utils.Upstreams, keys *KeyPool) (WeatherProvider, error) {
+	switch name {
+	case ProviderWeatherAPI:
+		wa := cfg.WeatherAPI
+		if len(wa.Keys()) == 0 {
+			return nil, newWeatherError(ErrTypeConfig, "WEATHER_API_KEY is not set", nil)
+		}
+		if wa.URL == "" {
+			return nil, newWeatherError(ErrTypeConfig, "WEATHER_API_URL is not set", nil)
+		}
+		if keys == nil {
+			keys = NewKeyPool(wa.Keys(), wa.KeyQuota, wa.QuotaPeriod)
+		} else {
+			keys.Update(wa.Keys(), wa.KeyQuota, wa.QuotaPeriod)
+		}
+		return NewWeatherAPIProvider(wa.URL, keys, upstreams.Client(name, upstreamOptions(cfg, name))), nil
+	case ProviderOpenMeteo:
+		return NewOpenMeteoProvider(
+			cfg.OpenMeteo.ForecastURL,
+			cfg.OpenMeteo.AirQualityURL,
+			cfg.OpenMeteo.GeocodingURL,
+			upstreams.Client(name, upstreamOptions(cfg, name)),
+		), nil
+	default:
+		return nil, newWeatherError(ErrTypeConfig, fmt.Sprintf("fournisseur météo inconnu: %q", name), nil)
+	}
+}
+
+// upstreamOptions traduit le réglage HTTP du fournisseur name.
+func upstreamOptions(cfg config.ProvidersConfig, name string) utils.UpstreamOptions {
+	u := cfg.Upstream(name)
+	return utils.UpstreamOptions{
+		Timeout: cfg.HTTPTimeout,
+		Retry: utils.RetryPolicy{
+			MaxRetries: u.MaxRetries,
+			BaseDelay:  u.RetryBaseDelay,
+			MaxDelay:   u.RetryMaxDelay,
+		},
+		Breaker: utils.BreakerPolicy{
+			Threshold:      u.BreakerThreshold,
+			OpenTimeout:    u.BreakerOpenTimeout,
+			HalfOpenProbes: u.BreakerHalfOpenProbes,
+		},
+		RateLimit: utils.RateLimitPolicy{
+			RPS:   u.RateLimit,
+			Burst: u.RateBurst,
+		},
+	}
+}
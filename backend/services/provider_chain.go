@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"weather-app-backend/models"
+	"weather-app-backend/utils"
+)
+
+// ProviderChain interroge une liste ordonnée de fournisseurs : en cas de
+// panne d'un fournisseur (erreur upstream / décodage), on passe au suivant.
+// Un fournisseur en échec est ignoré pendant `cooldown`.
+type ProviderChain struct {
+	providers []WeatherProvider
+	cooldown  time.Duration
+	health    *healthTracker
+}
+
+// NewProviderChain crée une chaîne de fournisseurs (ordre = priorité).
+func NewProviderChain(cooldown time.Duration, providers ...WeatherProvider) *ProviderChain {
+	return &ProviderChain{providers: providers, cooldown: cooldown, health: newHealthTracker(providers)}
+}
+
+// Health retourne l'état de chaque fournisseur et le nombre de bascules.
+func (c *ProviderChain) Health() ([]ProviderHealth, int) {
+	return c.health.snapshot()
+}
+
+func (c *ProviderChain) members() []WeatherProvider { return c.providers }
+
+// Name retourne les fournisseurs de la chaîne, ex. "weatherapi>openmeteo".
+func (c *ProviderChain) Name() string {
+	names := make([]string, 0, len(c.providers))
+	for _, p := range c.providers {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, ">")
+}
+
+func (c *ProviderChain) Current(ctx context.Context, location string) (*models.Weather, error) {
+	return c.weather(ctx, func(p WeatherProvider) (*models.Weather, error) {
+		return p.Current(ctx, location)
+	})
+}
+
+func (c *ProviderChain) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	return c.weather(ctx, func(p WeatherProvider) (*models.Weather, error) {
+		return p.Forecast(ctx, location, days)
+	})
+}
+
+func (c *ProviderChain) Alerts(ctx context.Context, location string) ([]WeatherAlert, error) {
+	var alerts []WeatherAlert
+	_, err := c.try(ctx, func(p WeatherProvider) error {
+		var err error
+		alerts, err = p.Alerts(ctx, location)
+		return err
+	})
+	return alerts, err
+}
+
+// weather exécute call sur la chaîne et note le fournisseur qui a répondu.
+func (c *ProviderChain) weather(ctx context.Context, call func(WeatherProvider) (*models.Weather, error)) (*models.Weather, error) {
+	var w *models.Weather
+	served, err := c.try(ctx, func(p WeatherProvider) error {
+		var err error
+		w, err = call(p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.Provider = served
+	return w, nil
+}
+
+// try appelle les fournisseurs dans l'ordre jusqu'au premier succès et
+// retourne le nom du fournisseur ayant répondu.
+func (c *ProviderChain) try(ctx context.Context, call func(WeatherProvider) error) (string, error) {
+	candidates := c.available(ctx)
+
+	var lastErr error
+	for i, p := range candidates {
+		err := call(p)
+		if err == nil {
+			c.health.recordSuccess(p.Name())
+			if i > 0 {
+				c.health.recordFallback()
+				utils.LoggerFrom(ctx).InfoContext(ctx, "fallback provider served the request", "provider", p.Name(), "failures", i)
+			}
+			return p.Name(), nil
+		}
+
+		// Annulation côté client : ce n'est pas la faute du fournisseur.
+		if ctx.Err() != nil {
+			return "", err
+		}
+		if !isFallbackError(err) {
+			return "", err
+		}
+
+		// la limite de débit sortante n'est pas une panne du fournisseur
+		if !errors.Is(err, utils.ErrRateLimited) {
+			c.health.recordFailure(p.Name(), err, c.cooldown)
+		}
+		lastErr = err
+		if i+1 < len(candidates) {
+			utils.LoggerFrom(ctx).WarnContext(ctx, "provider failed, falling back", "provider", p.Name(), "next", candidates[i+1].Name(), "error", err)
+		} else {
+			utils.LoggerFrom(ctx).WarnContext(ctx, "provider failed, no provider left", "provider", p.Name(), "error", err)
+		}
+	}
+	return "", lastErr
+}
+
+// available retourne les fournisseurs hors cooldown ; si tous sont en
+// cooldown, on les tente quand même plutôt que d'échouer sans essayer.
+func (c *ProviderChain) available(ctx context.Context) []WeatherProvider {
+	var out []WeatherProvider
+	for _, p := range c.providers {
+		if c.health.isHealthy(p.Name()) {
+			out = append(out, p)
+		} else {
+			utils.LoggerFrom(ctx).DebugContext(ctx, "skipping provider in cooldown", "provider", p.Name())
+		}
+	}
+	if len(out) == 0 {
+		return c.providers
+	}
+	return out
+}
+
+// isFallbackError indique si l'erreur justifie d'essayer le fournisseur suivant.
+func isFallbackError(err error) bool {
+	var werr *WeatherError
+	if !errors.As(err, &werr) {
+		return false
+	}
+	switch werr.Type {
+	case ErrTypeUpstream, ErrTypeDecode, ErrTypeQuota, ErrTypeAuth, ErrTypeRateLimited:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProviderHealth est l'état de santé exposé d'un fournisseur.
+type ProviderHealth struct {
+	Name                string     `json:"name"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	TotalFailures       int        `json:"total_failures"`
+	TotalSuccesses      int        `json:"total_successes"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailure         *time.Time `json:"last_failure,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	CooldownUntil       *time.Time `json:"cooldown_until,omitempty"`
+}
+
+// healthTracker conserve l'état des fournisseurs entre les requêtes.
+type healthTracker struct {
+	mu        sync.Mutex
+	states    map[string]*ProviderHealth
+	fallbacks int
+	now       func() time.Time
+}
+
+// newHealthTracker crée le suivi des fournisseurs donnés (tous sains).
+func newHealthTracker(providers []WeatherProvider) *healthTracker {
+	h := &healthTracker{states: make(map[string]*ProviderHealth), now: time.Now}
+	for _, p := range providers {
+		h.state(p.Name())
+	}
+	return h
+}
+
+// state retourne (en le créant) l'état d'un fournisseur ; mu doit être tenu.
+func (h *healthTracker) state(name string) *ProviderHealth {
+	s, ok := h.states[name]
+	if !ok {
+		s = &ProviderHealth{Name: name}
+		h.states[name] = s
+	}
+	return s
+}
+
+func (h *healthTracker) isHealthy(name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.state(name)
+	return s.CooldownUntil == nil || !h.now().Before(*s.CooldownUntil)
+}
+
+func (h *healthTracker) recordSuccess(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.state(name)
+	now := h.now()
+	s.ConsecutiveFailures = 0
+	s.TotalSuccesses++
+	s.LastSuccess = &now
+	s.CooldownUntil = nil
+}
+
+func (h *healthTracker) recordFailure(name string, err error, cooldown time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.state(name)
+	now := h.now()
+	until := now.Add(cooldown)
+	s.ConsecutiveFailures++
+	s.TotalFailures++
+	s.LastError = utils.Redact(err.Error())
+	s.LastFailure = &now
+	s.CooldownUntil = &until
+}
+
+func (h *healthTracker) recordFallback() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallbacks++
+}
+
+// snapshot copie l'état courant, trié par nom.
+func (h *healthTracker) snapshot() ([]ProviderHealth, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	out := make([]ProviderHealth, 0, len(h.states))
+	for _, s := range h.states {
+		cp := *s
+		cp.Healthy = cp.CooldownUntil == nil || !now.Before(*cp.CooldownUntil)
+		out = append(out, cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, h.fallbacks
+}
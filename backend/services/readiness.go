@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"weather-app-backend/utils"
+)
+
+// Statuts d'un composant vérifié par Readiness.
+const (
+	StatusOK        = "ok"
+	StatusDown      = "down"
+	StatusDisabled  = "disabled"  // composant désactivé (cache)
+	StatusUnchecked = "unchecked" // fournisseur sans sonde (fournisseur de test, intégration)
+)
+
+// ComponentCheck est l'état d'un composant : configuration, fournisseur
+// ("provider:weatherapi") ou cache.
+type ComponentCheck struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+
+	// Fournisseurs : état du disjoncteur et date de la sonde, dont le
+	// résultat est réutilisé pendant health.probe_interval (Cached).
+	Breaker   string     `json:"breaker,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	Cached    bool       `json:"cached,omitempty"`
+
+	Cache *CacheStats `json:"cache,omitempty"`
+}
+
+// Readiness est le résultat de /api/health/ready.
+type Readiness struct {
+	Ready      bool             `json:"ready"`
+	Components []ComponentCheck `json:"components"`
+}
+
+// prober est implémenté par les fournisseurs qui savent vérifier à peu de
+// frais qu'ils sont joignables : les URL retournées sont appelées sans
+// paramètres ni clé, ce qui ne consomme aucun quota.
+type prober interface {
+	probeURLs() []string
+}
+
+// memberLister est implémenté par les combinaisons de fournisseurs.
+type memberLister interface {
+	members() []WeatherProvider
+}
+
+// Readiness vérifie que le service peut répondre : configuration valide et
+// au moins un fournisseur joignable dont le disjoncteur n'est pas ouvert.
+// L'état du cache est rapporté sans influer sur le résultat.
+func (s *WeatherService) Readiness(ctx context.Context) Readiness {
+	st := s.state.Load()
+	var r Readiness
+
+	start := time.Now()
+	cfgCheck := ComponentCheck{Name: "config", Status: StatusOK}
+	if err := st.cfg.Validate(); err != nil {
+		cfgCheck.Status = StatusDown
+		cfgCheck.Error = utils.Redact(err.Error())
+	}
+	cfgCheck.LatencyMs = millis(time.Since(start))
+	r.Components = append(r.Components, cfgCheck)
+
+	breakers := make(map[string]string)
+	for _, u := range s.UpstreamStats() {
+		breakers[u.Name] = u.Breaker.State
+	}
+	providers := []WeatherProvider{st.base}
+	if m, ok := st.base.(memberLister); ok {
+		providers = m.members()
+	}
+	checks := make([]ComponentCheck, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks[i] = s.probes.check(ctx, p, breakers[p.Name()], st.cfg.Health.ProbeInterval, st.cfg.Health.ProbeTimeout)
+		}()
+	}
+	wg.Wait()
+
+	available := false
+	for _, c := range checks {
+		if c.Status != StatusDown {
+			available = true
+		}
+	}
+	r.Components = append(r.Components, checks...)
+
+	cacheCheck := ComponentCheck{Name: "cache", Status: StatusDisabled}
+	if stats, ok := s.CacheStats(); ok {
+		cacheCheck.Status = StatusOK
+		cacheCheck.Cache = &stats
+	}
+	r.Components = append(r.Components, cacheCheck)
+
+	r.Ready = cfgCheck.Status == StatusOK && available
+	return r
+}
+
+// probeCache conserve le dernier résultat de sonde de chaque fournisseur
+// pour ne pas solliciter les fournisseurs à chaque appel de l'orchestrateur.
+type probeCache struct {
+	client *http.Client
+
+	mu      sync.Mutex
+	entries map[string]*probeEntry
+}
+
+// probeEntry est le résultat de la dernière sonde ; mu n'est tenu que
+// pendant une sonde, pour qu'une seule soit en cours par fournisseur.
+type probeEntry struct {
+	mu      sync.Mutex
+	err     error
+	latency time.Duration
+	at      time.Time
+}
+
+// newProbeCache sonde sans passer par les transports des fournisseurs : ni
+// nouvelle tentative, ni disjoncteur, ni limite de débit, ni mesure.
+func newProbeCache() *probeCache {
+	return &probeCache{client: &http.Client{Transport: http.DefaultTransport}, entries: make(map[string]*probeEntry)}
+}
+
+// check retourne l'état du fournisseur p, en le sondant si le dernier
+// résultat a plus de interval.
+func (c *probeCache) check(ctx context.Context, p WeatherProvider, breaker string, interval, timeout time.Duration) ComponentCheck {
+	check := ComponentCheck{Name: "provider:" + p.Name(), Status: StatusUnchecked, Breaker: breaker}
+	pr, ok := p.(prober)
+	if !ok {
+		if breaker == utils.BreakerOpen {
+			check.Status = StatusDown
+			check.Error = "circuit breaker is open"
+		}
+		return check
+	}
+	urls := pr.probeURLs()
+	key := p.Name() + " " + strings.Join(urls, " ")
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &probeEntry{}
+		c.entries[key] = e
+	}
+	c.mu.Unlock()
+
+	e.mu.Lock()
+	check.Cached = !e.at.IsZero() && time.Since(e.at) < interval
+	if !check.Cached {
+		// le résultat sert aux appels suivants : il ne doit pas dépendre
+		// de l'annulation de la requête qui l'a déclenché
+		pctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		start := time.Now()
+		e.err = c.probe(pctx, urls)
+		e.latency = time.Since(start)
+		e.at = time.Now()
+		cancel()
+	}
+	err, latency, at := e.err, e.latency, e.at
+	e.mu.Unlock()
+
+	check.Status = StatusOK
+	check.LatencyMs = millis(latency)
+	check.CheckedAt = &at
+	switch {
+	case err != nil:
+		check.Status = StatusDown
+		check.Error = utils.Redact(err.Error())
+	case breaker == utils.BreakerOpen:
+		check.Status = StatusDown
+		check.Error = "circuit breaker is open"
+	}
+	return check
+}
+
+// probe appelle chaque URL : toute réponse HTTP inférieure à 500 (même
+// 400 ou 401, faute de paramètres ou de clé) prouve que le fournisseur est
+// joignable.
+func (c *probeCache) probe(ctx context.Context, urls []string) error {
+	var errs []error
+	for _, u := range urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			errs = append(errs, fmt.Errorf("%s answered %s", req.URL.Host, resp.Status))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// millis convertit une durée en millisecondes (JSON).
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// TieredCache empile plusieurs caches (ex. mémoire puis disque) : la lecture
+// descend les niveaux et remonte l'entrée trouvée dans les niveaux supérieurs,
+// l'écriture touche tous les niveaux.
+type TieredCache struct {
+	tiers []Cache
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// NewTieredCache crée un cache à plusieurs niveaux (le plus rapide en premier).
+func NewTieredCache(tiers ...Cache) *TieredCache {
+	return &TieredCache{tiers: tiers}
+}
+
+func (t *TieredCache) Get(key string) (CacheEntry, bool) {
+	for i, tier := range t.tiers {
+		entry, ok := tier.Get(key)
+		if !ok {
+			continue
+		}
+		for _, upper := range t.tiers[:i] {
+			upper.Set(key, entry)
+		}
+		t.count(func(s *CacheStats) {
+			if entry.Fresh(time.Now()) {
+				s.Hits++
+			} else {
+				s.StaleHits++
+			}
+		})
+		return entry, true
+	}
+	t.count(func(s *CacheStats) { s.Misses++ })
+	return CacheEntry{}, false
+}
+
+func (t *TieredCache) Set(key string, entry CacheEntry) {
+	for _, tier := range t.tiers {
+		tier.Set(key, entry)
+	}
+}
+
+func (t *TieredCache) Delete(key string) {
+	for _, tier := range t.tiers {
+		tier.Delete(key)
+	}
+}
+
+// Compact compacte chacun des niveaux qui le permet.
+func (t *TieredCache) Compact() {
+	for _, tier := range t.tiers {
+		if c, ok := tier.(Compacter); ok {
+			c.Compact()
+		}
+	}
+}
+
+// Flush vide chacun des niveaux qui le permet.
+func (t *TieredCache) Flush() error {
+	var errs []error
+	for _, tier := range t.tiers {
+		if f, ok := tier.(Flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Items retourne les entrées de tous les niveaux, décrites par le plus
+// rapide qui les contient.
+func (t *TieredCache) Items() []CacheItem {
+	seen := make(map[string]bool)
+	var items []CacheItem
+	for _, tier := range t.tiers {
+		l, ok := tier.(Lister)
+		if !ok {
+			continue
+		}
+		for _, item := range l.Items() {
+			if !seen[item.Key] {
+				seen[item.Key] = true
+				items = append(items, item)
+			}
+		}
+	}
+	sortItems(items)
+	return items
+}
+
+// Stats retourne les lectures vues par l'ensemble des niveaux ; les
+// évictions et la taille cumulent ceux de chaque niveau.
+func (t *TieredCache) Stats() CacheStats {
+	t.mu.Lock()
+	s := t.stats
+	t.mu.Unlock()
+
+	for _, tier := range t.tiers {
+		ts := tier.Stats()
+		s.Evictions += ts.Evictions
+		s.Entries = max(s.Entries, ts.Entries)
+		s.Capacity += ts.Capacity
+		s.Bytes += ts.Bytes
+		s.MaxBytes += ts.MaxBytes
+	}
+	return s
+}
+
+// Tiers retourne les statistiques de chaque niveau.
+func (t *TieredCache) Tiers() []CacheStats {
+	out := make([]CacheStats, 0, len(t.tiers))
+	for _, tier := range t.tiers {
+		out = append(out, tier.Stats())
+	}
+	return out
+}
+
+func (t *TieredCache) count(update func(*CacheStats)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	update(&t.stats)
+}
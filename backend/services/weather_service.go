@@ -2,12 +2,17 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"net/http"
-	"net/url"
-	"os"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"weather-app-backend/config"
 	"weather-app-backend/models"
@@ -18,12 +23,15 @@ import (
 type WeatherErrorType string
 
 const (
-	ErrTypeBadRequest WeatherErrorType = "bad_request"
-	ErrTypeNotFound   WeatherErrorType = "not_found"
-	ErrTypeUpstream   WeatherErrorType = "upstream_error"
-	ErrTypeConfig     WeatherErrorType = "config_error"
-	ErrTypeDecode     WeatherErrorType = "decode_error"
-	ErrTypeUnknown    WeatherErrorType = "unknown_error"
+	ErrTypeBadRequest  WeatherErrorType = "bad_request"
+	ErrTypeNotFound    WeatherErrorType = "not_found"
+	ErrTypeUpstream    WeatherErrorType = "upstream_error"
+	ErrTypeConfig      WeatherErrorType = "config_error"
+	ErrTypeDecode      WeatherErrorType = "decode_error"
+	ErrTypeQuota       WeatherErrorType = "quota_exceeded" // quota du fournisseur atteint
+	ErrTypeAuth        WeatherErrorType = "auth_error"     // clé refusée par le fournisseur (401/403)
+	ErrTypeRateLimited WeatherErrorType = "rate_limited"   // limite de débit sortante atteinte
+	ErrTypeUnknown     WeatherErrorType = "unknown_error"
 )
 
 // WeatherError est une erreur riche utilisée par le service.
@@ -33,11 +41,12 @@ type WeatherError struct {
 	Cause   error
 }
 
+// Error masque les secrets : la cause peut contenir l'URL appelée (clé API).
 func (e *WeatherError) Error() string {
 	if e.Cause != nil {
-		return fmt.Sprintf("%s: %s (%v)", e.Type, e.Message, e.Cause)
+		return utils.Redact(fmt.Sprintf("%s: %s (%v)", e.Type, e.Message, e.Cause))
 	}
-	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+	return utils.Redact(fmt.Sprintf("%s: %s", e.Type, e.Message))
 }
 
 func (e *WeatherError) Unwrap() error {
@@ -49,264 +58,328 @@ func newWeatherError(t WeatherErrorType, msg string, cause error) *WeatherError
 	return &WeatherError{Type: t, Message: msg, Cause: cause}
 }
 
-// GetWeatherForCity récupère conditions + prévisions pour une ville donnée.
-func GetWeatherForCity(ctx context.Context, city string) (*models.Weather, error) {
-	log.Printf("[weather] incoming request for city=%q\n", city)
+// forecastDays est le nombre de jours demandé au fournisseur.
+const forecastDays = 7
+
+// WeatherService regroupe les fournisseurs, le cache et la configuration
+// utilisés par les handlers. La configuration peut être rechargée à chaud
+// (Reload) sans interrompre les requêtes en cours.
+type WeatherService struct {
+	state  atomic.Pointer[serviceState]
+	logger *slog.Logger
+
+	mu        sync.Mutex // sérialise les rechargements
+	fixed     WeatherProvider
+	cache     Cache            // conservé d'un rechargement à l'autre
+	keys      *KeyPool         // compteurs des clés WeatherAPI, idem
+	upstreams *utils.Upstreams // clients HTTP et compteurs de nouvelles tentatives, idem
+	tasks     *backgroundTasks // rafraîchissements du cache en cours, attendus par Shutdown
+	compact   chan struct{}    // fermé par Shutdown pour arrêter la compaction périodique
+	compacted chan struct{}    // fermé quand la compaction périodique est arrêtée
+	errCounts errorCounter     // erreurs retournées, par type
+	probes    *probeCache      // dernières sondes de disponibilité des fournisseurs
+}
 
-	if city == "" {
-		return nil, newWeatherError(ErrTypeBadRequest, "paramètre 'city' manquant", nil)
+// serviceState est l'ensemble immuable remplacé d'un bloc à chaque rechargement.
+type serviceState struct {
+	cfg      *config.Config
+	base     WeatherProvider // chaîne ou ensemble (suivi de santé)
+	provider WeatherProvider // base + déduplication + cache
+}
+
+// healthReporter est implémenté par les combinaisons de fournisseurs qui
+// suivent l'état de leurs membres (ProviderChain, EnsembleProvider).
+type healthReporter interface {
+	Health() ([]ProviderHealth, int)
+}
+
+// NewWeatherService construit le service à partir de la configuration ;
+// logger reçoit les journaux du service et des fournisseurs (nil = aucun).
+func NewWeatherService(cfg *config.Config, logger *slog.Logger) (*WeatherService, error) {
+	s := &WeatherService{logger: utils.OrDiscard(logger), tasks: newBackgroundTasks(), probes: newProbeCache()}
+	if err := s.Reload(cfg); err != nil {
+		return nil, err
 	}
+	return s, nil
+}
 
-	apiKey, err := config.GetWeatherAPIKey()
+// NewWeatherServiceWithProvider construit un service sur un fournisseur
+// déjà assemblé (tests, intégrations), sans cache ni déduplication ajoutés.
+// Un rechargement ne remplace alors que la configuration.
+func NewWeatherServiceWithProvider(cfg *config.Config, p WeatherProvider, logger *slog.Logger) *WeatherService {
+	s := &WeatherService{fixed: p, logger: utils.OrDiscard(logger), tasks: newBackgroundTasks(), probes: newProbeCache()}
+	s.state.Store(&serviceState{cfg: cfg, base: p, provider: p})
+	return s
+}
+
+// Reload applique une nouvelle configuration (déjà validée) : fournisseurs,
+// durées de vie du cache et seuils d'alerte sont remplacés atomiquement.
+// Le contenu du cache est conservé ; sa taille, son dossier disque et
+// l'intervalle de compaction ne changent qu'au redémarrage. En cas d'erreur, l'état précédent reste actif.
+func (s *WeatherService) Reload(cfg *config.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fixed != nil {
+		s.state.Store(&serviceState{cfg: cfg, base: s.fixed, provider: s.fixed})
+		return nil
+	}
+
+	keys := s.keys
+	if keys == nil {
+		keys = NewKeyPool(nil, 0, cfg.Providers.WeatherAPI.QuotaPeriod)
+	}
+	if s.upstreams == nil {
+		s.upstreams = utils.NewUpstreams()
+	}
+	base, err := NewProviderFromConfig(cfg.Providers, s.upstreams, keys)
 	if err != nil {
-		werr := newWeatherError(ErrTypeConfig, err.Error(), err)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
+		return err
+	}
+	if s.keys == nil {
+		s.keys = keys
 	}
 
-	baseURL := os.Getenv("WEATHER_API_URL")
-	if baseURL == "" {
-		werr := newWeatherError(ErrTypeConfig, "WEATHER_API_URL is not set", nil)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
+	next := &serviceState{cfg: cfg, base: base}
+	next.provider = NewCoalescingProvider(base)
+	if cfg.Cache.Enabled {
+		if s.cache == nil {
+			s.cache = NewCacheFromConfig(cfg.Cache, s.logger.With("component", "cache"))
+			if c, ok := s.cache.(Compacter); ok && cfg.Cache.DiskCompactInterval > 0 {
+				s.startCompaction(c, cfg.Cache.DiskCompactInterval)
+			}
+		}
+		cached := NewCachedProvider(next.provider, s.cache, CacheTTLsFromConfig(cfg.Cache))
+		cached.tasks = s.tasks
+		next.provider = cached
 	}
 
-	u, err := url.Parse(baseURL)
-	if err != nil {
-		werr := newWeatherError(ErrTypeConfig, "WEATHER_API_URL invalide", err)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
+	s.state.Store(next)
+	s.logger.Info("weather service configured", "provider", base.Name(), "mode", cfg.Providers.Mode, "cache", cfg.Cache.Enabled)
+	return nil
+}
+
+// Config retourne la configuration active du service.
+func (s *WeatherService) Config() *config.Config {
+	return s.state.Load().cfg
+}
+
+// ProvidersHealth retourne l'état de chaque fournisseur et le nombre de
+// bascules (vide si le fournisseur ne suit pas sa santé).
+func (s *WeatherService) ProvidersHealth() ([]ProviderHealth, int) {
+	if h, ok := s.state.Load().base.(healthReporter); ok {
+		return h.Health()
 	}
+	return nil, 0
+}
 
-	q := u.Query()
-	q.Set("key", apiKey)
-	q.Set("q", city)
-	q.Set("lang", "fr")
-	q.Set("days", "7")
-	q.Set("aqi", "yes")
-	q.Set("alerts", "no")
-	u.RawQuery = q.Encode()
+// KeyUsage retourne l'utilisation des clés WeatherAPI (vide sans WeatherAPI).
+func (s *WeatherService) KeyUsage() []KeyUsage {
+	if s.keys == nil {
+		return nil
+	}
+	return s.keys.Usage()
+}
 
-	log.Printf("[weather] calling external API: %s\n", u.String())
+// UpstreamStats retourne l'activité HTTP de chaque fournisseur (tentatives,
+// nouvelles tentatives, abandons).
+func (s *WeatherService) UpstreamStats() []utils.UpstreamStats {
+	if s.upstreams == nil {
+		return nil
+	}
+	return s.upstreams.Stats()
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		werr := newWeatherError(ErrTypeUnknown, "impossible de créer la requête HTTP", err)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
+// CacheStats retourne les statistiques du cache ; ok vaut false s'il est désactivé.
+func (s *WeatherService) CacheStats() (CacheStats, bool) {
+	st := s.state.Load()
+	if _, cached := st.provider.(*CachedProvider); !cached {
+		return CacheStats{}, false
 	}
+	return s.cache.Stats(), true
+}
 
-	resp, err := utils.HTTPClient().Do(req)
-	if err != nil {
-		werr := newWeatherError(ErrTypeUpstream, "échec de l’appel à l’API météo externe", err)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
-	}
-	defer resp.Body.Close()
-
-	log.Printf("[weather] external API status=%d\n", resp.StatusCode)
-
-	// Gestion dédiée selon le code HTTP de WeatherAPI
-	if resp.StatusCode == http.StatusBadRequest {
-		werr := newWeatherError(ErrTypeBadRequest, "la ville demandée est invalide ou mal formée", nil)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
-	}
-	if resp.StatusCode == http.StatusNotFound {
-		werr := newWeatherError(ErrTypeNotFound, "ville ou ressource météo introuvable", nil)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
-	}
-	if resp.StatusCode >= 500 {
-		werr := newWeatherError(ErrTypeUpstream, "l’API météo externe rencontre un problème (erreur 5xx)", nil)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
-	}
-	if resp.StatusCode != http.StatusOK {
-		werr := newWeatherError(ErrTypeUpstream, fmt.Sprintf("réponse inattendue de l’API météo (status %d)", resp.StatusCode), nil)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
-	}
-
-	// Struct pour forecast.json
-	var raw struct {
-		Location struct {
-			Name    string  `json:"name"`
-			Region  string  `json:"region"`
-			Country string  `json:"country"`
-			Lat     float64 `json:"lat"`
-			Lon     float64 `json:"lon"`
-		} `json:"location"`
-		Current struct {
-			TempC      float64 `json:"temp_c"`
-			FeelsLikeC float64 `json:"feelslike_c"`
-			Humidity   int     `json:"humidity"`
-			WindKph    float64 `json:"wind_kph"`
-			WindDeg    int     `json:"wind_degree"`
-			WindDir    string  `json:"wind_dir"`
-			PressureMb float64 `json:"pressure_mb"`
-			VisKm      float64 `json:"vis_km"`
-			UV         float64 `json:"uv"`
-			Cloud      int     `json:"cloud"`
-			Condition  struct {
-				Text string `json:"text"`
-				Icon string `json:"icon"`
-				Code int    `json:"code"`
-			} `json:"condition"`
-			AirQuality map[string]float64 `json:"air_quality"` // nécessite aqi=yes et abonnement adéquat
-		} `json:"current"`
-		Forecast struct {
-			Forecastday []struct {
-				Date string `json:"date"`
-				Day  struct {
-					MaxtempC          float64 `json:"maxtemp_c"`
-					MintempC          float64 `json:"mintemp_c"`
-					AvgtempC          float64 `json:"avgtemp_c"`
-					MaxwindKph        float64 `json:"maxwind_kph"`
-					TotalprecipMm     float64 `json:"totalprecip_mm"`
-					AvgvisKm          float64 `json:"avgvis_km"`
-					Avghumidity       float64 `json:"avghumidity"`
-					DailyWillItRain   int     `json:"daily_will_it_rain"`
-					DailyChanceOfRain int     `json:"daily_chance_of_rain"`
-					DailyWillItSnow   int     `json:"daily_will_it_snow"`
-					DailyChanceOfSnow int     `json:"daily_chance_of_snow"`
-					Condition         struct {
-						Text string `json:"text"`
-						Icon string `json:"icon"`
-						Code int    `json:"code"`
-					} `json:"condition"`
-					UV float64 `json:"uv"`
-				} `json:"day"`
-				Hour []struct {
-					Time      string  `json:"time"`
-					TempC     float64 `json:"temp_c"`
-					Condition struct {
-						Text string `json:"text"`
-						Icon string `json:"icon"`
-						Code int    `json:"code"`
-					} `json:"condition"`
-					ChanceOfRain int     `json:"chance_of_rain"`
-					WindKph      float64 `json:"wind_kph"`
-					GustKph      float64 `json:"gust_kph"`
-					PressureMb   float64 `json:"pressure_mb"`
-					UV           float64 `json:"uv"`
-				} `json:"hour"`
-				Astro struct {
-					Sunrise   string `json:"sunrise"`
-					Sunset    string `json:"sunset"`
-					MoonPhase string `json:"moon_phase"`
-				} `json:"astro"`
-			} `json:"forecastday"`
-		} `json:"forecast"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		werr := newWeatherError(ErrTypeDecode, "impossible de décoder la réponse de l’API météo", err)
-		log.Println("[weather] ERROR:", werr)
-		return nil, werr
-	}
-
-	// Construire la partie "current"
-	w := &models.Weather{
-		City:        raw.Location.Name,
-		Country:     raw.Location.Country,
-		Region:      raw.Location.Region,
-		Latitude:    raw.Location.Lat,
-		Longitude:   raw.Location.Lon,
-		Temperature: raw.Current.TempC,
-		FeelsLike:   raw.Current.FeelsLikeC,
-		Condition:   raw.Current.Condition.Text,
-		// l’API renvoie souvent des URLs sans protocole complet, on préfixe en https si besoin
-		ConditionIconURL: ensureHTTPSIcon(raw.Current.Condition.Icon),
-		Humidity:         raw.Current.Humidity,
-		WindKph:          raw.Current.WindKph,
-		WindDegree:       raw.Current.WindDeg,
-		WindDir:          raw.Current.WindDir,
-		PressureMb:       raw.Current.PressureMb,
-		VisibilityKm:     raw.Current.VisKm,
-		UV:               raw.Current.UV,
-		Cloud:            raw.Current.Cloud,
-	}
-
-	// Qualité de l’air : AQI global (par ex. "us-epa-index")
-	if idx, ok := raw.Current.AirQuality["us-epa-index"]; ok {
-		w.AirQualityIndex = idx
-	}
-
-	// Prévisions journalières (limitées à 3 jours pour rester lisible)
-	for i, d := range raw.Forecast.Forecastday {
-		if i >= 3 {
-			break
+// CacheItems retourne les entrées du cache dont la clé commence par
+// prefix ; ok vaut false si le cache est désactivé ou ne sait pas les lister.
+func (s *WeatherService) CacheItems(prefix string) (items []CacheItem, ok bool) {
+	if _, ok := s.CacheStats(); !ok {
+		return nil, false
+	}
+	l, ok := s.cache.(Lister)
+	if !ok {
+		return nil, false
+	}
+	items = []CacheItem{}
+	for _, item := range l.Items() {
+		if strings.HasPrefix(item.Key, prefix) {
+			items = append(items, item)
 		}
-		fd := models.ForecastDay{
-			Date:          d.Date,
-			MinTemp:       d.Day.MintempC,
-			MaxTemp:       d.Day.MaxtempC,
-			AvgTemp:       d.Day.AvgtempC,
-			Condition:     d.Day.Condition.Text,
-			ConditionIcon: ensureHTTPSIcon(d.Day.Condition.Icon),
-			ChanceOfRain:  d.Day.DailyChanceOfRain,
-			ChanceOfSnow:  d.Day.DailyChanceOfSnow,
-			WindMaxKph:    d.Day.MaxwindKph,
-			GustMaxKph:    0, // non fourni directement au niveau Day
-			Sunrise:       d.Astro.Sunrise,
-			Sunset:        d.Astro.Sunset,
-			MoonPhase:     d.Astro.MoonPhase,
-			RiskThunder:   isThunderRisk(d.Day.Condition.Code),
+	}
+	return items, true
+}
+
+// PurgeCache supprime l'entrée key du cache (de tous ses niveaux) ; il
+// retourne false si elle n'existait pas.
+func (s *WeatherService) PurgeCache(key string) bool {
+	items, ok := s.CacheItems(key)
+	if !ok || !slices.ContainsFunc(items, func(item CacheItem) bool { return item.Key == key }) {
+		return false
+	}
+	s.cache.Delete(key)
+	s.logger.Info("cache entry purged", "key", key)
+	return true
+}
+
+// startCompaction compacte c toutes les interval, jusqu'à Shutdown : les
+// entrées périmées jamais relues quittent le disque sans attendre un
+// redémarrage.
+func (s *WeatherService) startCompaction(c Compacter, interval time.Duration) {
+	s.compact, s.compacted = make(chan struct{}), make(chan struct{})
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.Compact()
+			case <-stop:
+				return
+			}
 		}
-		w.ForecastDays = append(w.ForecastDays, fd)
-	}
-
-	// Prévisions horaires : on prend les 24 prochaines heures si dispo
-	if len(raw.Forecast.Forecastday) > 0 {
-		for _, h := range raw.Forecast.Forecastday[0].Hour {
-			w.Hourly = append(w.Hourly, models.ForecastHour{
-				Time:         h.Time,
-				Temp:         h.TempC,
-				Condition:    h.Condition.Text,
-				ChanceOfRain: h.ChanceOfRain,
-				WindKph:      h.WindKph,
-				GustKph:      h.GustKph,
-				PressureMb:   h.PressureMb,
-				UV:           h.UV,
-			})
+	}(s.compact, s.compacted)
+}
+
+// Shutdown arrête le service après la fin des requêtes : la compaction
+// périodique s'arrête, plus aucun rafraîchissement n'est lancé, ceux en
+// cours sont attendus jusqu'à l'échéance de ctx (puis annulés), le cache
+// est vidé sur disque et les derniers compteurs sont journalisés. L'erreur signale un arrêt incomplet.
+func (s *WeatherService) Shutdown(ctx context.Context) error {
+	var errs []error
+	s.mu.Lock()
+	if s.compact != nil {
+		close(s.compact)
+		<-s.compacted
+		s.compact = nil
+	}
+	s.mu.Unlock()
+	if err := s.tasks.close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("waiting for background refreshes: %w", err))
+	}
+	if f, ok := s.cache.(Flusher); ok {
+		if err := f.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("flushing cache: %w", err))
 		}
 	}
 
-	// Dériver quelques alertes/risk simples à partir des valeurs
-	w.Alerts = deriveAlerts(w)
+	if stats, ok := s.CacheStats(); ok {
+		s.logger.Info("final cache stats", "entries", stats.Entries, "hits", stats.Hits, "stale_hits", stats.StaleHits,
+			"misses", stats.Misses, "evictions", stats.Evictions, "bytes", stats.Bytes)
+	}
+	for _, u := range s.UpstreamStats() {
+		s.logger.Info("final upstream stats", "provider", u.Name, "requests", u.Requests, "attempts", u.Attempts,
+			"retries", u.Retries, "breaker_opens", u.Breaker.Opens, "rate_limited", u.RateLimit.Rejected)
+	}
+	for _, k := range s.KeyUsage() {
+		s.logger.Info("final key usage", "key", k.ID, "calls", k.Calls, "total_calls", k.TotalCalls)
+	}
+	return errors.Join(errs...)
+}
 
-	log.Printf("[weather] success city=%q temp=%.1f condition=%q, days=%d, hourly=%d\n",
-		w.City, w.Temperature, w.Condition, len(w.ForecastDays), len(w.Hourly))
+// endSpan termine span en y reportant err (nil en cas de succès).
+func endSpan(span trace.Span, err error) {
+	utils.SpanError(span, err)
+	span.End()
+}
 
-	return w, nil
+// ErrorCounts retourne le nombre d'erreurs retournées par type : celui
+// d'une WeatherError, sinon "timeout", "canceled" ou "internal".
+func (s *WeatherService) ErrorCounts() map[WeatherErrorType]uint64 {
+	return s.errCounts.snapshot()
 }
 
-// ensureHTTPSIcon s’assure que l’URL d’icône est complète.
-func ensureHTTPSIcon(icon string) string {
-	if icon == "" {
-		return ""
+// errorCounter compte les erreurs par type.
+type errorCounter struct {
+	mu     sync.Mutex
+	counts map[WeatherErrorType]uint64
+}
+
+func (c *errorCounter) add(err error) {
+	var werr *WeatherError
+	typ := WeatherErrorType("internal")
+	switch {
+	case errors.As(err, &werr):
+		typ = werr.Type
+	case errors.Is(err, context.DeadlineExceeded):
+		typ = "timeout"
+	case errors.Is(err, context.Canceled):
+		typ = "canceled"
 	}
-	// WeatherAPI renvoie souvent //cdn.weatherapi.com/...
-	if icon[:2] == "//" {
-		return "https:" + icon
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[WeatherErrorType]uint64)
 	}
-	return icon
+	c.counts[typ]++
 }
 
-// isThunderRisk détermine si un code condition correspond à un risque d’orage.
-func isThunderRisk(code int) bool {
-	// Liste non exhaustive de codes orage (voir docs WeatherAPI)
-	switch code {
-	case 1087, 1273, 1276, 1279, 1282:
-		return true
-	default:
-		return false
+func (c *errorCounter) snapshot() map[WeatherErrorType]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[WeatherErrorType]uint64, len(c.counts))
+	for typ, n := range c.counts {
+		counts[typ] = n
 	}
+	return counts
+}
+
+// GetWeatherForCity récupère conditions + prévisions pour une ville donnée.
+func (s *WeatherService) GetWeatherForCity(ctx context.Context, city string) (_ *models.Weather, err error) {
+	start := time.Now()
+	ctx, span := utils.StartSpan(ctx, "WeatherService.GetWeatherForCity", attribute.String("city", city))
+	defer func() { endSpan(span, err) }()
+	logger := s.logger.With("city", city)
+	ctx = utils.WithLogger(ctx, logger)
+	logger.DebugContext(ctx, "weather requested")
+
+	if city == "" {
+		err := newWeatherError(ErrTypeBadRequest, "paramètre 'city' manquant", nil)
+		s.errCounts.add(err)
+		return nil, err
+	}
+
+	// un seul état pour toute la requête, même si un rechargement intervient
+	st := s.state.Load()
+
+	w, err := st.provider.Forecast(ctx, city, forecastDays)
+	if err != nil {
+		s.errCounts.add(err)
+		logger.ErrorContext(ctx, "weather request failed", "provider", st.provider.Name(), "error", err, "latency", time.Since(start))
+		return nil, err
+	}
+
+	if w.Provider == "" {
+		w.Provider = st.provider.Name()
+	}
+
+	// Dériver quelques alertes/risk simples à partir des valeurs
+	_, alertsSpan := utils.StartSpan(ctx, "deriveAlerts")
+	w.Alerts = deriveAlerts(w, st.cfg.Alerts)
+	alertsSpan.SetAttributes(attribute.Int("alerts", len(w.Alerts)))
+	alertsSpan.End()
+
+	span.SetAttributes(attribute.String("provider", w.Provider), attribute.Bool("cache_hit", w.Cached), attribute.Bool("stale", w.Stale))
+
+	logger.InfoContext(ctx, "weather served",
+		"provider", w.Provider, "cache_hit", w.Cached, "stale", w.Stale, "temp", w.Temperature,
+		"condition", w.Condition, "days", len(w.ForecastDays), "hourly", len(w.Hourly), "latency", time.Since(start))
+
+	return w, nil
 }
 
 // deriveAlerts génère des "alertes" simplifiées à partir des données.
-func deriveAlerts(w *models.Weather) []models.WeatherAlert {
+func deriveAlerts(w *models.Weather, thresholds config.AlertsConfig) []models.WeatherAlert {
 	var alerts []models.WeatherAlert
 
 	// Orages violents
@@ -323,7 +396,7 @@ func deriveAlerts(w *models.Weather) []models.WeatherAlert {
 
 	// Pluie abondante (si chance de pluie importante)
 	for _, d := range w.ForecastDays {
-		if d.ChanceOfRain >= 70 {
+		if d.ChanceOfRain >= thresholds.RainChance {
 			alerts = append(alerts, models.WeatherAlert{
 				Type:     "pluie_abondante",
 				Severity: "modéré",
@@ -335,7 +408,7 @@ func deriveAlerts(w *models.Weather) []models.WeatherAlert {
 
 	// Vents forts (rafales approximées via vent max)
 	for _, d := range w.ForecastDays {
-		if d.WindMaxKph >= 50 {
+		if d.WindMaxKph >= thresholds.WindKph {
 			alerts = append(alerts, models.WeatherAlert{
 				Type:     "vents_forts",
 				Severity: "élevé",
@@ -345,9 +418,9 @@ func deriveAlerts(w *models.Weather) []models.WeatherAlert {
 		}
 	}
 
-	// Alerte chaleur (si max > 30°C)
+	// Alerte chaleur
 	for _, d := range w.ForecastDays {
-		if d.MaxTemp >= 30 {
+		if d.MaxTemp >= thresholds.HeatC {
 			alerts = append(alerts, models.WeatherAlert{
 				Type:     "chaleur",
 				Severity: "élevé",
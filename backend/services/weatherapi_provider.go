@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"weather-app-backend/config"
+	"weather-app-backend/models"
+	"weather-app-backend/utils"
+)
+
+// ProviderWeatherAPI identifie le fournisseur WeatherAPI.com.
+const ProviderWeatherAPI = config.ProviderWeatherAPI
+
+// WeatherAPIProvider implémente WeatherProvider sur WeatherAPI.com (forecast.json).
+type WeatherAPIProvider struct {
+	baseURL string
+	keys    *KeyPool
+	client  *http.Client
+}
+
+// NewWeatherAPIProvider crée un fournisseur WeatherAPI.
+// baseURL pointe sur l'endpoint forecast.json ; les appels sont répartis
+// entre les clés du pool.
+func NewWeatherAPIProvider(baseURL string, keys *KeyPool, client *http.Client) *WeatherAPIProvider {
+	return &WeatherAPIProvider{baseURL: baseURL, keys: keys, client: client}
+}
+
+// KeyUsage retourne l'utilisation de chaque clé du pool.
+func (p *WeatherAPIProvider) KeyUsage() []KeyUsage {
+	return p.keys.Usage()
+}
+
+func (p *WeatherAPIProvider) Name() string { return ProviderWeatherAPI }
+
+// probeURLs : sans clé, WeatherAPI répond 401 sans décompter d'appel.
+func (p *WeatherAPIProvider) probeURLs() []string { return []string{p.baseURL} }
+
+// Current retourne les conditions actuelles (sans prévisions).
+func (p *WeatherAPIProvider) Current(ctx context.Context, location string) (*models.Weather, error) {
+	raw, err := p.fetch(ctx, location, 1, false)
+	if err != nil {
+		return nil, err
+	}
+	return buildWeatherModel(raw, false), nil
+}
+
+// Forecast retourne conditions actuelles + prévisions journalières et horaires.
+func (p *WeatherAPIProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	raw, err := p.fetch(ctx, location, days, false)
+	if err != nil {
+		return nil, err
+	}
+	return buildWeatherModel(raw, true), nil
+}
+
+// Alerts retourne les alertes officielles renvoyées par WeatherAPI.
+func (p *WeatherAPIProvider) Alerts(ctx context.Context, location string) ([]WeatherAlert, error) {
+	// un jour suffit pour les alertes
+	raw, err := p.fetch(ctx, location, 1, true)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]WeatherAlert, 0, len(raw.Alerts.Alert))
+	for _, a := range raw.Alerts.Alert {
+		alerts = append(alerts, WeatherAlert{
+			Headline: a.Headline,
+			Severity: a.Severity,
+			Areas:    a.Areas,
+			Event:    a.Event,
+			Desc:     a.Desc,
+		})
+	}
+	return alerts, nil
+}
+
+// fetch appelle forecast.json avec la prochaine clé du pool ; une clé
+// refusée (quota, 401/403) est écartée et l'appel repart avec la suivante.
+// Un appel refusé localement (disjoncteur, limite de débit) est rendu à
+// la clé : il n'a pas atteint WeatherAPI.
+func (p *WeatherAPIProvider) fetch(ctx context.Context, location string, days int, withAlerts bool) (_ *weatherAPIResponse, err error) {
+	ctx, span := utils.StartSpan(ctx, "weatherapi.fetch",
+		attribute.String("provider", ProviderWeatherAPI), attribute.String("location", location), attribute.Int("days", days))
+	defer func() { endSpan(span, err) }()
+
+	for attempt := 0; attempt <= p.keys.Len(); attempt++ {
+		key, err := p.keys.Acquire()
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := p.fetchWithKey(ctx, key, location, days, withAlerts)
+		if errors.Is(err, utils.ErrCircuitOpen) || errors.Is(err, utils.ErrRateLimited) {
+			p.keys.Refund(key)
+		}
+		var werr *WeatherError
+		if errors.As(err, &werr) && (werr.Type == ErrTypeQuota || werr.Type == ErrTypeAuth) {
+			id := p.keys.Reject(key, werr)
+			utils.LoggerFrom(ctx).WarnContext(ctx, "weatherapi key set aside until next period",
+				"provider", ProviderWeatherAPI, "key", id, "reason", werr.Message)
+			continue
+		}
+		return raw, err
+	}
+	return nil, newWeatherError(ErrTypeAuth, "aucune clé WeatherAPI utilisable", nil)
+}
+
+// fetchWithKey appelle forecast.json et décode la réponse brute.
+func (p *WeatherAPIProvider) fetchWithKey(ctx context.Context, key, location string, days int, withAlerts bool) (*weatherAPIResponse, error) {
+	u, err := url.Parse(p.baseURL)
+	if err != nil {
+		return nil, newWeatherError(ErrTypeConfig, "WEATHER_API_URL invalide", err)
+	}
+
+	alerts := "no"
+	if withAlerts {
+		alerts = "yes"
+	}
+
+	q := u.Query()
+	q.Set("key", key)
+	q.Set("q", location) // ville / coord / code pays, etc.
+	q.Set("lang", "fr")
+	q.Set("days", strconv.Itoa(days))
+	q.Set("aqi", "yes")
+	q.Set("alerts", alerts)
+	u.RawQuery = q.Encode()
+
+	logger := utils.LoggerFrom(ctx).With("provider", ProviderWeatherAPI)
+	logger.DebugContext(ctx, "calling upstream", "url", utils.Redact(u.String()))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, newWeatherError(ErrTypeUnknown, "impossible de créer la requête HTTP", err)
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.WarnContext(ctx, "upstream call failed", "error", utils.Redact(err.Error()), "latency", time.Since(start))
+		return nil, callError(err)
+	}
+	defer resp.Body.Close()
+
+	logger.InfoContext(ctx, "upstream response", "status", resp.StatusCode, "latency", time.Since(start))
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, keyError(resp.StatusCode, resp.Body)
+	}
+	if werr := handleUpstreamStatus(resp.StatusCode); werr != nil {
+		return nil, werr
+	}
+
+	_, span := utils.StartSpan(ctx, "weatherapi.decode")
+	defer span.End()
+	var raw weatherAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		werr := newWeatherError(ErrTypeDecode, "impossible de décoder la réponse de l’API météo", err)
+		span.RecordError(werr)
+		return nil, werr
+	}
+	return &raw, nil
+}
+
+// callError traduit l'échec d'un appel HTTP (réseau, disjoncteur ouvert,
+// limite de débit sortante) en WeatherError.
+func callError(err error) *WeatherError {
+	if errors.Is(err, utils.ErrRateLimited) {
+		return newWeatherError(ErrTypeRateLimited, "limite d’appels vers l’API météo externe atteinte", err)
+	}
+	return newWeatherError(ErrTypeUpstream, "échec de l’appel à l’API météo externe", err)
+}
+
+// handleUpstreamStatus traduit le code HTTP de WeatherAPI en WeatherError.
+func handleUpstreamStatus(status int) *WeatherError {
+	switch {
+	case status == http.StatusOK:
+		return nil
+	case status == http.StatusBadRequest:
+		return newWeatherError(ErrTypeBadRequest, "la ville demandée est invalide ou mal formée", nil)
+	case status == http.StatusNotFound:
+		return newWeatherError(ErrTypeNotFound, "ville ou ressource météo introuvable", nil)
+	case status >= 500:
+		return newWeatherError(ErrTypeUpstream, "l’API météo externe rencontre un problème (erreur 5xx)", nil)
+	default:
+		return newWeatherError(ErrTypeUpstream, fmt.Sprintf("réponse inattendue de l’API météo (status %d)", status), nil)
+	}
+}
+
+// weatherAPIErrorBody est le corps des réponses d'erreur de WeatherAPI.
+type weatherAPIErrorBody struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// weatherAPIQuotaExceeded est le code d'erreur "quota mensuel dépassé".
+const weatherAPIQuotaExceeded = 2007
+
+// keyError traduit un 401/403 de WeatherAPI : quota dépassé (code 2007) ou
+// clé refusée (absente, invalide, désactivée...).
+func keyError(status int, body io.Reader) *WeatherError {
+	var e weatherAPIErrorBody
+	_ = json.NewDecoder(io.LimitReader(body, 4096)).Decode(&e)
+
+	detail := fmt.Sprintf("status %d, code %d: %s", status, e.Error.Code, e.Error.Message)
+	if e.Error.Code == weatherAPIQuotaExceeded {
+		return newWeatherError(ErrTypeQuota, "quota de la clé WeatherAPI dépassé ("+detail+")", nil)
+	}
+	return newWeatherError(ErrTypeAuth, "clé WeatherAPI refusée ("+detail+")", nil)
+}
+
+// weatherAPICondition est le bloc "condition" commun de WeatherAPI.
+type weatherAPICondition struct {
+	Text string `json:"text"`
+	Icon string `json:"icon"`
+	Code int    `json:"code"`
+}
+
+// weatherAPIResponse décrit la réponse de forecast.json.
+type weatherAPIResponse struct {
+	Location struct {
+		Name    string  `json:"name"`
+		Region  string  `json:"region"`
+		Country string  `json:"country"`
+		Lat     float64 `json:"lat"`
+		Lon     float64 `json:"lon"`
+	} `json:"location"`
+	Current struct {
+		TempC      float64             `json:"temp_c"`
+		FeelsLikeC float64             `json:"feelslike_c"`
+		Humidity   int                 `json:"humidity"`
+		WindKph    float64             `json:"wind_kph"`
+		WindDeg    int                 `json:"wind_degree"`
+		WindDir    string              `json:"wind_dir"`
+		PressureMb float64             `json:"pressure_mb"`
+		VisKm      float64             `json:"vis_km"`
+		UV         float64             `json:"uv"`
+		Cloud      int                 `json:"cloud"`
+		Condition  weatherAPICondition `json:"condition"`
+		AirQuality map[string]float64  `json:"air_quality"` // nécessite aqi=yes et abonnement adéquat
+	} `json:"current"`
+	Forecast struct {
+		Forecastday []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxtempC          float64             `json:"maxtemp_c"`
+				MintempC          float64             `json:"mintemp_c"`
+				AvgtempC          float64             `json:"avgtemp_c"`
+				MaxwindKph        float64             `json:"maxwind_kph"`
+				TotalprecipMm     float64             `json:"totalprecip_mm"`
+				AvgvisKm          float64             `json:"avgvis_km"`
+				Avghumidity       float64             `json:"avghumidity"`
+				DailyWillItRain   int                 `json:"daily_will_it_rain"`
+				DailyChanceOfRain int                 `json:"daily_chance_of_rain"`
+				DailyWillItSnow   int                 `json:"daily_will_it_snow"`
+				DailyChanceOfSnow int                 `json:"daily_chance_of_snow"`
+				Condition         weatherAPICondition `json:"condition"`
+				UV                float64             `json:"uv"`
+			} `json:"day"`
+			Hour []struct {
+				Time         string              `json:"time"`
+				TempC        float64             `json:"temp_c"`
+				Condition    weatherAPICondition `json:"condition"`
+				ChanceOfRain int                 `json:"chance_of_rain"`
+				WindKph      float64             `json:"wind_kph"`
+				GustKph      float64             `json:"gust_kph"`
+				PressureMb   float64             `json:"pressure_mb"`
+				UV           float64             `json:"uv"`
+			} `json:"hour"`
+			Astro struct {
+				Sunrise   string `json:"sunrise"`
+				Sunset    string `json:"sunset"`
+				MoonPhase string `json:"moon_phase"`
+			} `json:"astro"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+	Alerts struct {
+		Alert []struct {
+			Headline string `json:"headline"`
+			Severity string `json:"severity"`
+			Areas    string `json:"areas"`
+			Event    string `json:"event"`
+			Desc     string `json:"desc"`
+		} `json:"alert"`
+	} `json:"alerts"`
+}
+
+// buildWeatherModel convertit la réponse WeatherAPI en models.Weather.
+func buildWeatherModel(raw *weatherAPIResponse, withForecast bool) *models.Weather {
+	// Construire la partie "current"
+	w := &models.Weather{
+		City:        raw.Location.Name,
+		Country:     raw.Location.Country,
+		Region:      raw.Location.Region,
+		Latitude:    raw.Location.Lat,
+		Longitude:   raw.Location.Lon,
+		Temperature: raw.Current.TempC,
+		FeelsLike:   raw.Current.FeelsLikeC,
+		Condition:   raw.Current.Condition.Text,
+		// l’API renvoie souvent des URLs sans protocole complet, on préfixe en https si besoin
+		ConditionIconURL: ensureHTTPSIcon(raw.Current.Condition.Icon),
+		Humidity:         raw.Current.Humidity,
+		WindKph:          raw.Current.WindKph,
+		WindDegree:       raw.Current.WindDeg,
+		WindDir:          raw.Current.WindDir,
+		PressureMb:       raw.Current.PressureMb,
+		VisibilityKm:     raw.Current.VisKm,
+		UV:               raw.Current.UV,
+		Cloud:            raw.Current.Cloud,
+	}
+
+	// Qualité de l’air : AQI global (par ex. "us-epa-index")
+	if idx, ok := raw.Current.AirQuality["us-epa-index"]; ok {
+		w.AirQualityIndex = idx
+	}
+
+	if !withForecast {
+		return w
+	}
+
+	// Prévisions journalières (limitées à 3 jours pour rester lisible)
+	for i, d := range raw.Forecast.Forecastday {
+		if i >= 3 {
+			break
+		}
+		fd := models.ForecastDay{
+			Date:          d.Date,
+			MinTemp:       d.Day.MintempC,
+			MaxTemp:       d.Day.MaxtempC,
+			AvgTemp:       d.Day.AvgtempC,
+			Condition:     d.Day.Condition.Text,
+			ConditionIcon: ensureHTTPSIcon(d.Day.Condition.Icon),
+			ChanceOfRain:  d.Day.DailyChanceOfRain,
+			ChanceOfSnow:  d.Day.DailyChanceOfSnow,
+			WindMaxKph:    d.Day.MaxwindKph,
+			GustMaxKph:    0, // non fourni directement au niveau Day
+			Sunrise:       d.Astro.Sunrise,
+			Sunset:        d.Astro.Sunset,
+			MoonPhase:     d.Astro.MoonPhase,
+			RiskThunder:   isThunderRisk(d.Day.Condition.Code),
+		}
+		w.ForecastDays = append(w.ForecastDays, fd)
+	}
+
+	// Prévisions horaires : on prend les 24 prochaines heures si dispo
+	if len(raw.Forecast.Forecastday) > 0 {
+		for _, h := range raw.Forecast.Forecastday[0].Hour {
+			w.Hourly = append(w.Hourly, models.ForecastHour{
+				Time:         h.Time,
+				Temp:         h.TempC,
+				Condition:    h.Condition.Text,
+				ChanceOfRain: h.ChanceOfRain,
+				WindKph:      h.WindKph,
+				GustKph:      h.GustKph,
+				PressureMb:   h.PressureMb,
+				UV:           h.UV,
+			})
+		}
+	}
+
+	return w
+}
+
+// ensureHTTPSIcon s’assure que l’URL d’icône est complète.
+func ensureHTTPSIcon(icon string) string {
+	if icon == "" {
+		return ""
+	}
+	// WeatherAPI renvoie souvent //cdn.weatherapi.com/...
+	if len(icon) >= 2 && icon[:2] == "//" {
+		return "https:" + icon
+	}
+	return icon
+}
+
+// isThunderRisk détermine si un code condition correspond à un risque d’orage.
+func isThunderRisk(code int) bool {
+	// Liste non exhaustive de codes orage (voir docs WeatherAPI)
+	switch code {
+	case 1087, 1273, 1276, 1279, 1282:
+		return true
+	default:
+		return false
+	}
+}
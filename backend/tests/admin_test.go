@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"weather-app-backend/handlers"
+	"weather-app-backend/services"
+)
+
+func TestAdminListener(t *testing.T) {
+	upstream := newWeatherAPIFixtureServer(t)
+	cfg := weatherAPIConfig(upstream.URL)
+	cfg.Admin.Token = "s3cret-admin"
+	svc := newService(t, cfg)
+	admin := httptest.NewServer(handlers.AdminHandler(svc))
+	defer admin.Close()
+
+	call := func(method, path, token string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, admin.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	for _, path := range []string{"/admin/build", "/admin/config", "/debug/pprof/"} {
+		if code, _ := call(http.MethodGet, path, "wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("%s: expected 401 with a wrong token, got %d", path, code)
+		}
+	}
+	for _, path := range []string{"/admin/build", "/admin/providers", "/admin/keys", "/debug/pprof/goroutine?debug=1"} {
+		if code, body := call(http.MethodGet, path, "s3cret-admin"); code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", path, code, body)
+		}
+	}
+
+	// configuration effective : les secrets sont masqués
+	_, body := call(http.MethodGet, "/admin/config", "s3cret-admin")
+	if strings.Contains(body, "s3cret-admin") || strings.Contains(body, "test-key") || !strings.Contains(body, `"path":"admin.token"`) {
+		t.Fatalf("unexpected config: %s", body)
+	}
+
+	// contenu du cache puis purge d'une entrée
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var cache struct {
+		Stats   services.CacheStats  `json:"stats"`
+		Entries []services.CacheItem `json:"entries"`
+	}
+	_, body = call(http.MethodGet, "/admin/cache?prefix=forecast", "s3cret-admin")
+	if err := json.Unmarshal([]byte(body), &cache); err != nil || len(cache.Entries) == 0 || !cache.Entries[0].Fresh {
+		t.Fatalf("unexpected cache listing %s (err %v)", body, err)
+	}
+	key := cache.Entries[0].Key
+	if code, _ := call(http.MethodDelete, "/admin/cache?key="+url.QueryEscape(key), "s3cret-admin"); code != http.StatusNoContent {
+		t.Fatalf("expected 204 on purge, got %d", code)
+	}
+	if code, _ := call(http.MethodDelete, "/admin/cache?key="+url.QueryEscape(key), "s3cret-admin"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 once purged, got %d", code)
+	}
+	if code, _ := call(http.MethodPost, "/admin/cache", "s3cret-admin"); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", code)
+	}
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-app-backend/handlers"
+	"weather-app-backend/utils"
+)
+
+func TestBreakerOpensHalfOpensAndCloses(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		<-release // appel d'essai volontairement lent
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	breaker := utils.NewBreaker("flaky", nil, utils.BreakerPolicy{Threshold: 2, OpenTimeout: 50 * time.Millisecond, HalfOpenProbes: 1})
+	client := &http.Client{Transport: breaker}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get(srv.URL); !errors.Is(err, utils.ErrCircuitOpen) {
+		t.Fatalf("expected the open breaker to fail fast, got %v", err)
+	}
+	if calls.Load() != 2 || breaker.Stats().State != utils.BreakerOpen {
+		t.Fatalf("expected open breaker after 2 calls, got %+v after %d calls", breaker.Stats(), calls.Load())
+	}
+
+	// après OpenTimeout, un seul appel d'essai passe ; les autres échouent vite
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+	done := make(chan error)
+	go func() {
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	for calls.Load() != 3 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := client.Get(srv.URL); !errors.Is(err, utils.ErrCircuitOpen) {
+		t.Fatalf("expected a second probe to be rejected, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+
+	if s := breaker.Stats(); s.State != utils.BreakerClosed || s.Opens != 1 || s.Rejected != 2 {
+		t.Fatalf("expected closed breaker after a successful probe, got %+v", s)
+	}
+}
+
+func TestBreakerIgnoresCanceledProbe(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		<-r.Context().Done() // le fournisseur ne répond plus
+	}))
+	defer srv.Close()
+
+	breaker := utils.NewBreaker("slow", nil, utils.BreakerPolicy{Threshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenProbes: 1})
+	client := &http.Client{Transport: breaker}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if s := breaker.Stats(); s.State != utils.BreakerOpen {
+		t.Fatalf("expected an open breaker, got %+v", s)
+	}
+
+	// l'appel d'essai est annulé par l'appelant : le disjoncteur reste
+	// demi-ouvert, sans fermer ni oublier les échecs
+	time.Sleep(30 * time.Millisecond)
+	failing.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected the probe to be canceled")
+	}
+	if s := breaker.Stats(); s.State != utils.BreakerHalfOpen || s.ConsecutiveFailures != 1 {
+		t.Fatalf("expected a half-open breaker keeping its failures, got %+v", s)
+	}
+
+	// le créneau d'essai est libéré : un nouvel appel d'essai peut partir
+	failing.Store(true)
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("expected a new probe, got %v", err)
+	}
+	resp.Body.Close()
+	if s := breaker.Stats(); s.State != utils.BreakerOpen || s.Opens != 2 {
+		t.Fatalf("expected the failed probe to reopen the breaker, got %+v", s)
+	}
+}
+
+func TestBreakerStateInHealth(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cfg := weatherAPIConfig(srv.URL)
+	cfg.Providers.WeatherAPI.HTTP.MaxRetries = 0
+	cfg.Providers.WeatherAPI.HTTP.BreakerThreshold = 1
+	svc := newService(t, cfg)
+
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err == nil {
+		t.Fatal("expected an upstream error")
+	}
+	if _, err := svc.GetWeatherForCity(context.Background(), "Lyon"); err == nil {
+		t.Fatal("expected the open breaker to fail")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected the provider to be called once, got %d", calls.Load())
+	}
+
+	rec := httptest.NewRecorder()
+	handlers.HealthHandler(svc)(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	var body struct {
+		Upstream []utils.UpstreamStats `json:"upstream"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding health: %v", err)
+	}
+	if len(body.Upstream) != 1 || body.Upstream[0].Breaker.State != utils.BreakerOpen || body.Upstream[0].Breaker.Rejected != 1 {
+		t.Fatalf("expected an open breaker in health, got %+v", body.Upstream)
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+)
+
+func TestNormalizeLocation(t *testing.T) {
+	cases := map[string]string{
+		"  São   Paulo ":   "sao paulo",
+		"SAINT-ÉTIENNE":    "saint-etienne",
+		"48.8534, 2.3488":  "48.85,2.35",
+		"Zürich":           "zurich",
+		"new\tyork":        "new york",
+		"Ville-d'Avray  ":  "ville-d'avray",
+		"-33.8688,151.209": "-33.87,151.21",
+	}
+	for in, want := range cases {
+		if got := services.NormalizeLocation(in); got != want {
+			t.Errorf("NormalizeLocation(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMemoryCacheLRUEviction(t *testing.T) {
+	c := services.NewMemoryCache(2)
+	entry := services.CacheEntry{Data: []byte("{}"), StoredAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute)}
+
+	c.Set("a", entry)
+	c.Set("b", entry)
+	c.Get("a") // "a" devient le plus récent
+	c.Set("c", entry)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("expected a to be kept")
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 || stats.Entries != 2 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	c := services.NewMemoryCache(10)
+	c.Set("old", services.CacheEntry{StoredAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(-time.Minute)})
+
+	if _, ok := c.Get("old"); ok {
+		t.Fatalf("expected expired entry to be a miss")
+	}
+}
+
+func TestCachedProviderServesFromCache(t *testing.T) {
+	fake := &fakeProvider{name: "cache-fake", weather: &models.Weather{City: "Paris", Latitude: 48.8534, Longitude: 2.3488}}
+	ttls := services.CacheTTLs{Current: time.Minute, Forecast: time.Minute, Alerts: time.Minute}
+	svc := newTestService(services.NewCachedProvider(fake, services.NewMemoryCache(10), ttls))
+
+	first, err := svc.GetWeatherForCity(context.Background(), "Paris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Cached {
+		t.Fatalf("first lookup should not be cached")
+	}
+
+	// Casse, accents, espaces et coordonnées résolues tombent sur la même entrée.
+	for _, q := range []string{"  paris ", "PARIS", "48.85,2.35"} {
+		w, err := svc.GetWeatherForCity(context.Background(), q)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", q, err)
+		}
+		if !w.Cached {
+			t.Fatalf("expected cache hit for %q", q)
+		}
+	}
+	if fake.calls != 1 {
+		t.Fatalf("expected a single upstream call, got %d", fake.calls)
+	}
+}
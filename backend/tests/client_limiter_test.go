@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"weather-app-backend/config"
+	"weather-app-backend/handlers"
+	"weather-app-backend/services"
+)
+
+// newLimitedHandler retourne /api/weather derrière un ClientLimiter réglé par tune.
+func newLimitedHandler(tune func(*config.RateLimitConfig)) (*handlers.ClientLimiter, http.HandlerFunc) {
+	cfg := config.Default()
+	cfg.RateLimit.Routes = map[string]config.RouteRate{"/api/weather": {RPS: 0.01, Burst: 2}}
+	tune(&cfg.RateLimit)
+	limiter := handlers.NewClientLimiter(services.NewWeatherServiceWithProvider(cfg, &fakeProvider{name: "fake"}, nil))
+	return limiter, limiter.Limit("/api/weather", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func limitedCall(h http.HandlerFunc, remote string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/weather?city=Paris", nil)
+	req.RemoteAddr = remote
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestClientLimiterPerClientAndRoute(t *testing.T) {
+	_, h := newLimitedHandler(func(*config.RateLimitConfig) {})
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		rec := limitedCall(h, "192.0.2.1:1234", nil)
+		if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != wantRemaining || rec.Header().Get("RateLimit-Limit") != "2" {
+			t.Fatalf("call %d: unexpected response %d %v", i+1, rec.Code, rec.Header())
+		}
+	}
+	rec := limitedCall(h, "192.0.2.1:1234", nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" || rec.Header().Get("RateLimit-Reset") == "" {
+		t.Fatalf("expected 429 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+
+	if rec := limitedCall(h, "192.0.2.2:1234", nil); rec.Code != http.StatusOK {
+		t.Fatalf("another client must have its own bucket, got %d", rec.Code)
+	}
+}
+
+func TestClientLimiterIdentifiesClients(t *testing.T) {
+	_, h := newLimitedHandler(func(c *config.RateLimitConfig) {
+		c.Routes["/api/weather"] = config.RouteRate{RPS: 0.01, Burst: 1}
+		c.TrustedProxies = []string{"10.0.0.0/8"}
+		c.ClientKeys = []string{"client-key-1234"}
+	})
+
+	// derrière le proxy de confiance : le client est la dernière adresse non fiable
+	proxied := map[string]string{"X-Forwarded-For": "203.0.113.9, 198.51.100.7, 10.0.0.2"}
+	if rec := limitedCall(h, "10.0.0.1:80", proxied); rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	proxied["X-Forwarded-For"] = "1.1.1.1, 198.51.100.7"
+	if rec := limitedCall(h, "10.0.0.1:80", proxied); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("a forged left-most entry must not change the client, got %d", rec.Code)
+	}
+
+	// X-Forwarded-For d'un client direct est ignoré
+	if rec := limitedCall(h, "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "203.0.113.9"}); rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if rec := limitedCall(h, "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "203.0.113.10"}); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("X-Forwarded-For from an untrusted peer must be ignored, got %d", rec.Code)
+	}
+
+	// une clé connue a son propre seau, quelle que soit l'adresse ; une clé inconnue non
+	if rec := limitedCall(h, "192.0.2.1:1234", map[string]string{"X-API-Key": "client-key-1234"}); rec.Code != http.StatusOK {
+		t.Fatalf("a known key must have its own bucket, got %d", rec.Code)
+	}
+	if rec := limitedCall(h, "192.0.2.1:1234", map[string]string{"X-API-Key": "made-up-key"}); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("an unknown key must fall back to the client IP, got %d", rec.Code)
+	}
+}
+
+func TestClientLimiterEvictsIdleBuckets(t *testing.T) {
+	limiter, h := newLimitedHandler(func(c *config.RateLimitConfig) {
+		c.IdleTimeout = 20 * time.Millisecond
+	})
+
+	limitedCall(h, "192.0.2.1:1234", nil)
+	limitedCall(h, "192.0.2.2:1234", nil)
+	if n := limiter.Clients(); n != 2 {
+		t.Fatalf("expected 2 buckets, got %d", n)
+	}
+	time.Sleep(30 * time.Millisecond)
+	limitedCall(h, "192.0.2.3:1234", nil)
+	if n := limiter.Clients(); n != 1 {
+		t.Fatalf("expected idle buckets to be evicted, got %d", n)
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+)
+
+func TestConcurrentRequestsShareOneUpstreamCall(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "weatherapi_forecast.json"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := weatherAPIConfig(srv.URL)
+	cfg.Cache.Enabled = false
+	svc := newService(t, cfg)
+
+	const n = 20
+	var (
+		wg      sync.WaitGroup
+		started sync.WaitGroup
+		errs    = make(chan error, n)
+	)
+	started.Add(n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			w, err := svc.GetWeatherForCity(context.Background(), "Paris")
+			if err == nil && w.City != "Paris" {
+				t.Errorf("unexpected city %q", w.City)
+			}
+			errs <- err
+		}()
+	}
+	started.Wait()
+	// laisser les requêtes rejoindre l'appel en vol avant de répondre
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected exactly 1 upstream hit for %d concurrent requests, got %d", n, got)
+	}
+}
+
+// blockingProvider attend `release` et note si son contexte a été annulé.
+type blockingProvider struct {
+	release  chan struct{}
+	canceled atomic.Bool
+}
+
+func (b *blockingProvider) Name() string { return "blocking" }
+
+func (b *blockingProvider) Current(ctx context.Context, location string) (*models.Weather, error) {
+	return b.Forecast(ctx, location, 1)
+}
+
+func (b *blockingProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	select {
+	case <-b.release:
+		return &models.Weather{City: location}, nil
+	case <-ctx.Done():
+		b.canceled.Store(true)
+		return nil, ctx.Err()
+	}
+}
+
+func (b *blockingProvider) Alerts(ctx context.Context, location string) ([]services.WeatherAlert, error) {
+	return nil, nil
+}
+
+func TestCancelledCallerDoesNotCancelSharedFetch(t *testing.T) {
+	inner := &blockingProvider{release: make(chan struct{})}
+	svc := newTestService(services.NewCoalescingProvider(inner))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := svc.GetWeatherForCity(ctx, "Nantes")
+		firstErr <- err
+	}()
+
+	secondErr := make(chan error, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_, err := svc.GetWeatherForCity(context.Background(), "Nantes")
+		secondErr <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled caller to get context.Canceled, got %v", err)
+	}
+	// l'abandon du client n'est pas compté comme une panne du fournisseur
+	if counts := svc.ErrorCounts(); counts["canceled"] != 1 || counts[services.ErrTypeUpstream] != 0 {
+		t.Fatalf("expected one canceled error, got %v", counts)
+	}
+
+	close(inner.release)
+	if err := <-secondErr; err != nil {
+		t.Fatalf("second caller should still get the shared result: %v", err)
+	}
+	if inner.canceled.Load() {
+		t.Fatalf("shared fetch must not be cancelled by one caller")
+	}
+}
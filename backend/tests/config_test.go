@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-app-backend/config"
+)
+
+func TestConfigLayersFileThenEnv(t *testing.T) {
+	t.Setenv("PORT", "7070")
+	t.Setenv("CACHE_TTL_CURRENT", "2m")
+
+	cfg, err := config.New(filepath.Join("testdata", "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Port != 7070 {
+		t.Fatalf("env should override file port, got %d", cfg.Server.Port)
+	}
+	if cfg.Providers.Mode != config.ModeEnsemble || cfg.Providers.Cooldown != 45*time.Second {
+		t.Fatalf("unexpected providers from file: %+v", cfg.Providers)
+	}
+	if cfg.Providers.WeatherAPI.APIKey != "file-key" || cfg.Providers.EnsembleWeights["openmeteo"] != 2 {
+		t.Fatalf("unexpected provider settings: %+v", cfg.Providers)
+	}
+	if cfg.Cache.TTLForecast != 15*time.Minute || cfg.Cache.TTLCurrent != 2*time.Minute {
+		t.Fatalf("unexpected cache TTLs: %+v", cfg.Cache)
+	}
+	// les clés absentes du fichier gardent leur valeur par défaut
+	if cfg.Cache.TTLAlerts != config.Default().Cache.TTLAlerts || cfg.Alerts.DefaultCity != "Paris" {
+		t.Fatalf("defaults lost: %+v / %+v", cfg.Cache, cfg.Alerts)
+	}
+	if cfg.Alerts.RainChance != 60 {
+		t.Fatalf("expected rain threshold 60, got %d", cfg.Alerts.RainChance)
+	}
+}
+
+func TestConfigTOML(t *testing.T) {
+	cfg, err := config.New(filepath.Join("testdata", "config.toml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Port != 9191 || cfg.Providers.HTTPTimeout != 2*time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Alerts.DefaultCity != "Lyon" || cfg.Alerts.HeatC != 28.5 {
+		t.Fatalf("unexpected alerts: %+v", cfg.Alerts)
+	}
+}
+
+func TestConfigValidationReportsAllErrors(t *testing.T) {
+	t.Setenv("WEATHER_PROVIDERS", "weatherapi,darksky")
+	t.Setenv("WEATHER_API_KEY", "")
+	t.Setenv("WEATHER_MODE", "random")
+	t.Setenv("SERVER_WRITE_TIMEOUT", "5s")   // plus court que request_timeout (10s)
+	t.Setenv("ADMIN_ADDR", "127.0.0.1:8080") // même port que server.port
+
+	_, err := config.New("")
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"api_key", `unknown provider "darksky"`, `providers.mode: "random"`, "server.write_timeout", "admin.addr"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestConfigRejectsUnweightedEnsemble(t *testing.T) {
+	cfg := config.Default()
+	cfg.Providers.Order = []string{"openmeteo"}
+	cfg.Providers.Mode = config.ModeEnsemble
+	cfg.Providers.EnsembleWeights = map[string]float64{"openmeteo": 0, "weatherapi": 1}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"providers.ensemble_weights.weatherapi: provider is not in providers.order", "at least one provider"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestConfigRejectsMalformedEnv(t *testing.T) {
+	t.Setenv("WEATHER_PROVIDER_COOLDOWN", "soon")
+
+	_, err := config.New("")
+	if err == nil || !strings.Contains(err.Error(), "WEATHER_PROVIDER_COOLDOWN") {
+		t.Fatalf("expected error naming the variable, got %v", err)
+	}
+}
+
+// writeEnvFile crée un fichier .env temporaire.
+func writeEnvFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+	return path
+}
+
+func TestConfigEnvFilesMergedKeyByKey(t *testing.T) {
+	base := writeEnvFile(t, ".env", "PORT=8081\nWEATHER_API_KEY=base-key\nWEATHER_API_URL=https://base.example/v1/forecast.json\nCACHE_TTL_ALERTS=1m\n")
+	overlay := writeEnvFile(t, ".env.production", "WEATHER_API_URL=https://prod.example/v1/forecast.json\n")
+	// PORT défini dans l'environnement réel ne doit plus masquer le reste du .env
+	env := map[string]string{"PORT": "9000"}
+
+	cfg, src, err := config.Load(config.Options{
+		EnvFiles: []string{base, overlay},
+		Lookup: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Port != 9000 || src["server.port"] != "env (PORT)" {
+		t.Fatalf("expected port from env, got %d (%s)", cfg.Server.Port, src["server.port"])
+	}
+	if cfg.Providers.WeatherAPI.APIKey != "base-key" {
+		t.Fatalf("expected key from base env file, got %q", cfg.Providers.WeatherAPI.APIKey)
+	}
+	if cfg.Providers.WeatherAPI.URL != "https://prod.example/v1/forecast.json" || !strings.HasPrefix(src["providers.weatherapi.url"], "env-file:"+overlay) {
+		t.Fatalf("expected URL from overlay, got %q (%s)", cfg.Providers.WeatherAPI.URL, src["providers.weatherapi.url"])
+	}
+	if cfg.Cache.TTLAlerts != time.Minute || src["cache.ttl_forecast"] != config.SourceDefault {
+		t.Fatalf("unexpected cache layering: %+v %v", cfg.Cache, src)
+	}
+}
+
+func TestWriteEffectiveRedactsSecrets(t *testing.T) {
+	cfg, src, err := config.Load(config.Options{
+		File:   filepath.Join("testdata", "config.yaml"),
+		Lookup: func(string) (string, bool) { return "", false },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := config.WriteEffective(&out, cfg, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "file-key") || !strings.Contains(out.String(), config.Redacted) {
+		t.Fatalf("secret not redacted:\n%s", out.String())
+	}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, "cache.ttl_forecast ") && !strings.HasSuffix(line, "file:testdata/config.yaml") {
+			t.Fatalf("expected file source, got %q", line)
+		}
+	}
+}
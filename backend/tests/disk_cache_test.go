@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"weather-app-backend/services"
+)
+
+func freshEntry(data string, ttl time.Duration) services.CacheEntry {
+	now := time.Now()
+	return services.CacheEntry{Data: []byte(data), StoredAt: now, ExpiresAt: now.Add(ttl), StaleUntil: now.Add(ttl)}
+}
+
+func TestDiskCacheSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := services.NewDiskCache(dir, 1<<20, nil)
+	if err != nil {
+		t.Fatalf("opening disk cache: %v", err)
+	}
+	c.Set("forecast:7|weatherapi|paris", freshEntry(`{"city":"Paris"}`, time.Hour))
+
+	// Nouveau processus : l'index est reconstruit depuis le disque.
+	reopened, err := services.NewDiskCache(dir, 1<<20, nil)
+	if err != nil {
+		t.Fatalf("reopening disk cache: %v", err)
+	}
+	entry, ok := reopened.Get("forecast:7|weatherapi|paris")
+	if !ok || !bytes.Equal(entry.Data, []byte(`{"city":"Paris"}`)) {
+		t.Fatalf("expected persisted entry, got ok=%v data=%s", ok, entry.Data)
+	}
+}
+
+func TestDiskCacheLeavesForeignFilesAlone(t *testing.T) {
+	dir := t.TempDir()
+	foreign := []string{"notes.txt", "other.json", "ABCDEF0123456789ABCDEF0123456789.json"}
+	for _, name := range foreign {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("not a cache entry"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// fichier temporaire d'une écriture interrompue : il appartient au cache
+	if err := os.WriteFile(filepath.Join(dir, ".tmp-123456"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := services.NewDiskCache(dir, 1<<20, nil); err != nil {
+		t.Fatalf("opening disk cache: %v", err)
+	}
+	for _, name := range foreign {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("expected %s to be left alone: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".tmp-123456")); !os.IsNotExist(err) {
+		t.Fatalf("expected the leftover temp file to be removed, got %v", err)
+	}
+}
+
+func TestDiskCacheRemovesTempFileWhenRenameFails(t *testing.T) {
+	dir := t.TempDir()
+	c, err := services.NewDiskCache(dir, 1<<20, nil)
+	if err != nil {
+		t.Fatalf("opening disk cache: %v", err)
+	}
+	// un dossier occupe le nom du fichier de l'entrée : le renommage échoue
+	const key = "forecast:7|weatherapi|paris"
+	sum := sha256.Sum256([]byte(key))
+	if err := os.Mkdir(filepath.Join(dir, hex.EncodeToString(sum[:16])+".json"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	c.Set(key, freshEntry(`{"city":"Paris"}`, time.Hour))
+
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, ".tmp-*")); len(leftovers) != 0 {
+		t.Fatalf("expected no temp file after a failed rename, got %v", leftovers)
+	}
+}
+
+func TestDiskCacheCompactionAndMaxSize(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := services.NewDiskCache(dir, 1<<20, nil)
+	if err != nil {
+		t.Fatalf("opening disk cache: %v", err)
+	}
+	c.Set("expired", freshEntry(`{}`, -time.Minute))
+	c.Set("valid", freshEntry(`{}`, time.Hour))
+	c.Compact()
+
+	if _, ok := c.Get("expired"); ok {
+		t.Fatalf("expected expired entry to be compacted")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("expected 1 file after compaction, got %d", len(files))
+	}
+
+	// Taille max minuscule : seule l'entrée la plus récente tient.
+	small, err := services.NewDiskCache(t.TempDir(), 250, nil)
+	if err != nil {
+		t.Fatalf("opening disk cache: %v", err)
+	}
+	small.Set("a", freshEntry(`{"city":"A"}`, time.Hour))
+	time.Sleep(5 * time.Millisecond)
+	small.Set("b", freshEntry(`{"city":"B"}`, time.Hour))
+
+	stats := small.Stats()
+	if stats.Bytes > stats.MaxBytes || stats.Evictions == 0 {
+		t.Fatalf("expected eviction under max size, got %+v", stats)
+	}
+	if _, ok := small.Get("b"); !ok {
+		t.Fatalf("expected most recent entry to be kept")
+	}
+}
+
+func TestTieredCachePromotesFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := services.NewDiskCache(dir, 1<<20, nil)
+	if err != nil {
+		t.Fatalf("opening disk cache: %v", err)
+	}
+	disk.Set("k", freshEntry(`{"city":"Lille"}`, time.Hour))
+
+	memory := services.NewMemoryCache(10)
+	tiered := services.NewTieredCache(memory, disk)
+
+	if _, ok := tiered.Get("k"); !ok {
+		t.Fatalf("expected hit from disk tier")
+	}
+	if _, ok := memory.Get("k"); !ok {
+		t.Fatalf("expected entry promoted to memory tier")
+	}
+}
+
+func TestServiceCompactsDiskCachePeriodically(t *testing.T) {
+	srv := newWeatherAPIFixtureServer(t)
+	dir := t.TempDir()
+	cfg := weatherAPIConfig(srv.URL)
+	cfg.Cache.DiskDir = dir
+	cfg.Cache.DiskCompactInterval = 10 * time.Millisecond
+	cfg.Cache.TTLForecast = time.Millisecond
+	cfg.Cache.StaleWhileRevalidate = 0
+	cfg.Cache.StaleIfError = 0
+	svc := newService(t, cfg)
+
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries := func() int {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		return len(files)
+	}
+	if entries() == 0 {
+		t.Fatal("expected the response to be written to disk")
+	}
+
+	// l'entrée périmée n'est jamais relue : seule la compaction la supprime
+	deadline := time.Now().Add(time.Second)
+	for entries() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the expired entry to be compacted away")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := svc.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+}
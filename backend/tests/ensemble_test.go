@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+)
+
+func TestEnsembleProviderBlendsForecasts(t *testing.T) {
+	a := &fakeProvider{name: "ens-a", weather: &models.Weather{
+		City:        "Paris",
+		Temperature: 20,
+		ForecastDays: []models.ForecastDay{
+			{Date: "2025-06-01", MaxTemp: 24, ChanceOfRain: 10},
+			{Date: "2025-06-02", MaxTemp: 20, ChanceOfRain: 80, RiskThunder: true},
+		},
+	}}
+	b := &fakeProvider{name: "ens-b", weather: &models.Weather{
+		City:        "Paris (b)",
+		Temperature: 26,
+		ForecastDays: []models.ForecastDay{
+			{Date: "2025-06-01", MaxTemp: 24, ChanceOfRain: 10},
+			{Date: "2025-06-02", MaxTemp: 30, ChanceOfRain: 20},
+		},
+	}}
+	// ens-a compte double
+	weights := map[string]float64{"ens-a": 2}
+	svc := newTestService(services.NewEnsembleProvider(weights, a, b))
+
+	w, err := svc.GetWeatherForCity(context.Background(), "Paris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.City != "Paris" || w.Provider != "ensemble(ens-a+ens-b)" {
+		t.Fatalf("unexpected base fields city=%q provider=%q", w.City, w.Provider)
+	}
+	if w.Temperature != 22 {
+		t.Fatalf("expected weighted temperature 22, got %v", w.Temperature)
+	}
+
+	agree, disagree := w.ForecastDays[0], w.ForecastDays[1]
+	if agree.Spread != 0 || agree.Confidence == nil || *agree.Confidence != 1 || agree.Sources != 2 {
+		t.Fatalf("expected full agreement on day 1, got %+v", agree)
+	}
+	if math.Abs(disagree.MaxTemp-23.33) > 0.01 || !disagree.RiskThunder {
+		t.Fatalf("unexpected merged day 2: %+v", disagree)
+	}
+	if disagree.Spread <= 0 || disagree.Confidence == nil || *disagree.Confidence >= *agree.Confidence {
+		t.Fatalf("expected disagreement on day 2, got spread=%v confidence=%v", disagree.Spread, disagree.Confidence)
+	}
+}
+
+func TestEnsembleProviderToleratesPartialFailure(t *testing.T) {
+	a := &fakeProvider{name: "ens-down", err: upstreamError()}
+	b := &fakeProvider{name: "ens-up", weather: &models.Weather{City: "Paris", Temperature: 18, ForecastDays: []models.ForecastDay{
+		{Date: "2025-06-01", MaxTemp: 24, ChanceOfRain: 10},
+	}}}
+	svc := newTestService(services.NewEnsembleProvider(nil, a, b))
+
+	w, err := svc.GetWeatherForCity(context.Background(), "Paris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Provider != "ensemble(ens-up)" || w.Temperature != 18 {
+		t.Fatalf("unexpected result provider=%q temp=%v", w.Provider, w.Temperature)
+	}
+	// une seule source sur deux : pas de désaccord mesurable, confiance divisée par deux
+	if day := w.ForecastDays[0]; day.Sources != 1 || day.Spread != 0 || day.Confidence == nil || *day.Confidence != 0.5 {
+		t.Fatalf("expected half confidence from a single source, got %+v", day)
+	}
+}
+
+func TestEnsembleProviderWithoutWeightedMemberFails(t *testing.T) {
+	a := &fakeProvider{name: "ens-zero", weather: &models.Weather{City: "Paris", Temperature: 18}}
+	svc := newTestService(services.NewEnsembleProvider(map[string]float64{"ens-zero": 0}, a))
+
+	w, err := svc.GetWeatherForCity(context.Background(), "Paris")
+	var werr *services.WeatherError
+	if w != nil || !errors.As(err, &werr) || werr.Type != services.ErrTypeConfig {
+		t.Fatalf("expected a config error, got %v, %v", w, err)
+	}
+}
+
+func TestEnsembleProviderKeepsZeroConfidence(t *testing.T) {
+	a := &fakeProvider{name: "ens-cold", weather: &models.Weather{City: "Paris", ForecastDays: []models.ForecastDay{
+		{Date: "2025-06-01", MaxTemp: 0, ChanceOfRain: 0},
+	}}}
+	b := &fakeProvider{name: "ens-hot", weather: &models.Weather{City: "Paris", ForecastDays: []models.ForecastDay{
+		{Date: "2025-06-01", MaxTemp: 30, ChanceOfRain: 100},
+	}}}
+	svc := newTestService(services.NewEnsembleProvider(nil, a, b))
+
+	w, err := svc.GetWeatherForCity(context.Background(), "Paris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// un désaccord total reste visible dans la réponse
+	body, _ := json.Marshal(w.ForecastDays[0])
+	if !strings.Contains(string(body), `"confidence":0`) {
+		t.Fatalf("expected a zero confidence in %s", body)
+	}
+}
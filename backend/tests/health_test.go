@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"weather-app-backend/handlers"
+	"weather-app-backend/services"
+)
+
+// ready appelle /api/health/ready et décode la réponse.
+func ready(t *testing.T, svc *services.WeatherService) (int, map[string]services.ComponentCheck) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handlers.ReadyHandler(svc)(rec, httptest.NewRequest(http.MethodGet, "/api/health/ready", nil))
+	var body services.Readiness
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding readiness: %v", err)
+	}
+	if body.Ready != (rec.Code == http.StatusOK) {
+		t.Fatalf("status %d does not match ready=%v", rec.Code, body.Ready)
+	}
+	components := make(map[string]services.ComponentCheck)
+	for _, c := range body.Components {
+		components[c.Name] = c
+	}
+	return rec.Code, components
+}
+
+func TestReadinessProbesProvidersOnce(t *testing.T) {
+	var probes atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// la sonde n'envoie pas de clé : 401 suffit à prouver que le fournisseur répond
+		if r.URL.Query().Get("key") != "" {
+			t.Errorf("probe sent an API key: %s", r.URL)
+		}
+		probes.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer upstream.Close()
+	svc := newService(t, weatherAPIConfig(upstream.URL))
+
+	code, components := ready(t, svc)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %+v", code, components)
+	}
+	p := components["provider:weatherapi"]
+	if components["config"].Status != services.StatusOK || p.Status != services.StatusOK || p.Cached || p.CheckedAt == nil ||
+		components["cache"].Status != services.StatusOK || components["cache"].Cache == nil {
+		t.Fatalf("unexpected components %+v", components)
+	}
+
+	// le résultat est réutilisé pendant health.probe_interval
+	_, components = ready(t, svc)
+	if !components["provider:weatherapi"].Cached || probes.Load() != 1 {
+		t.Fatalf("expected a cached probe, got %d probes and %+v", probes.Load(), components["provider:weatherapi"])
+	}
+}
+
+func TestReadinessFailsWhenNoProviderIsReachable(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	cfg := weatherAPIConfig(failing.URL)
+	cfg.Providers.Order = []string{"weatherapi", "openmeteo"}
+	cfg.Providers.OpenMeteo.GeocodingURL = closed.URL + "/v1/search"
+	cfg.Cache.Enabled = false
+	svc := newService(t, cfg)
+
+	code, components := ready(t, svc)
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", code)
+	}
+	for _, name := range []string{"provider:weatherapi", "provider:openmeteo"} {
+		if c := components[name]; c.Status != services.StatusDown || c.Error == "" || c.Breaker != "closed" {
+			t.Fatalf("expected %s to be down, got %+v", name, c)
+		}
+	}
+	if components["cache"].Status != services.StatusDisabled {
+		t.Fatalf("expected a disabled cache, got %+v", components["cache"])
+	}
+
+	// la vivacité ne dépend pas des fournisseurs
+	rec := httptest.NewRecorder()
+	handlers.LiveHandler()(rec, httptest.NewRequest(http.MethodGet, "/api/health/live", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected live to answer 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-app-backend/config"
+	"weather-app-backend/handlers"
+	"weather-app-backend/services"
+)
+
+func TestKeyPoolRoundRobinWithinQuota(t *testing.T) {
+	pool := services.NewKeyPool([]string{"first-key-0001", "second-key-0002"}, 2, config.QuotaDay)
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		key, err := pool.Acquire()
+		if err != nil {
+			t.Fatalf("unexpected error on call %d: %v", i+1, err)
+		}
+		got = append(got, key)
+	}
+	if strings.Join(got, " ") != "first-key-0001 second-key-0002 first-key-0001 second-key-0002" {
+		t.Fatalf("expected round-robin, got %v", got)
+	}
+
+	_, err := pool.Acquire()
+	var werr *services.WeatherError
+	if !errors.As(err, &werr) || werr.Type != services.ErrTypeQuota {
+		t.Fatalf("expected quota error once all keys are used up, got %v", err)
+	}
+	for _, u := range pool.Usage() {
+		if u.Calls != 2 || u.Remaining == nil || *u.Remaining != 0 || u.Available {
+			t.Fatalf("unexpected usage: %+v", u)
+		}
+	}
+}
+
+// newKeyCheckingServer simule WeatherAPI : une clé hors quota, une clé
+// refusée, les autres valides.
+func newKeyCheckingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "weatherapi_forecast.json"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("key") {
+		case "quota-key-aaaa":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":2007,"message":"API key has exceeded calls per month quota."}}`))
+		case "revoked-key-bbbb":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":2006,"message":"API key provided is invalid"}}`))
+		default:
+			_, _ = w.Write(body)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWeatherAPIRotatesKeysOnQuotaAndAuthErrors(t *testing.T) {
+	srv := newKeyCheckingServer(t)
+	cfg := weatherAPIConfig(srv.URL)
+	cfg.Providers.WeatherAPI.APIKey = ""
+	cfg.Providers.WeatherAPI.APIKeys = []string{"quota-key-aaaa", "revoked-key-bbbb", "valid-key-cccc"}
+	cfg.Cache.Enabled = false
+	svc := newService(t, cfg)
+
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("expected the valid key to be used, got %v", err)
+	}
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	usage := svc.KeyUsage()
+	if len(usage) != 3 {
+		t.Fatalf("expected 3 keys, got %+v", usage)
+	}
+	if usage[0].Blocked != "quota" || usage[1].Blocked != "auth" || usage[0].Calls != 1 || usage[1].Calls != 1 {
+		t.Fatalf("expected rejected keys to be set aside after one call: %+v", usage)
+	}
+	if usage[2].Calls != 2 || !usage[2].Available {
+		t.Fatalf("expected the valid key to serve both calls: %+v", usage[2])
+	}
+}
+
+func TestAdminKeysEndpoint(t *testing.T) {
+	srv := newKeyCheckingServer(t)
+	cfg := weatherAPIConfig(srv.URL)
+	cfg.Providers.WeatherAPI.APIKey = "valid-key-cccc"
+	svc := newService(t, cfg)
+	handler := handlers.AdminHandler(svc)
+
+	call := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/keys", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := call("Bearer anything"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without admin token configured, got %d", rec.Code)
+	}
+
+	next := *cfg
+	next.Admin.Token = "s3cret-admin"
+	if err := svc.Reload(&next); err != nil {
+		t.Fatalf("unexpected reload error: %v", err)
+	}
+	if rec := call("Bearer wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with a wrong token, got %d", rec.Code)
+	}
+
+	rec := call("Bearer s3cret-admin")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "valid-key-cccc") {
+		t.Fatalf("admin endpoint leaked a key: %s", rec.Body.String())
+	}
+	var body struct {
+		Keys []services.KeyUsage `json:"keys"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || len(body.Keys) != 1 || body.Keys[0].ID != "key-1 (…cccc)" {
+		t.Fatalf("unexpected body %+v (err %v)", body, err)
+	}
+}
+
+func TestKeyPoolIgnoresLocallyRejectedCalls(t *testing.T) {
+	var calls atomic.Int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	breakerCfg := weatherAPIConfig(failing.URL)
+	breakerCfg.Providers.WeatherAPI.HTTP.MaxRetries = 0
+	breakerCfg.Providers.WeatherAPI.HTTP.BreakerThreshold = 1
+	breakerCfg.Cache.Enabled = false
+
+	limitedCfg := weatherAPIConfig(newWeatherAPIFixtureServer(t).URL)
+	limitedCfg.Providers.WeatherAPI.HTTP.RateLimit = 0.1 // un appel toutes les 10 s
+	limitedCfg.Providers.WeatherAPI.HTTP.RateBurst = 1
+	limitedCfg.Cache.Enabled = false
+
+	for name, cfg := range map[string]*config.Config{"open breaker": breakerCfg, "rate limited": limitedCfg} {
+		t.Run(name, func(t *testing.T) {
+			svc := newService(t, cfg)
+			_, _ = svc.GetWeatherForCity(context.Background(), "Paris")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if _, err := svc.GetWeatherForCity(ctx, "Lyon"); err == nil {
+				t.Fatal("expected the second call to be rejected locally")
+			}
+			if usage := svc.KeyUsage(); len(usage) != 1 || usage[0].Calls != 1 || usage[0].TotalCalls != 1 {
+				t.Fatalf("expected only the call sent upstream to be counted: %+v", usage)
+			}
+		})
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected the failing provider to be called once, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"weather-app-backend/handlers"
+)
+
+func TestMetricsScrape(t *testing.T) {
+	upstream := newWeatherAPIFixtureServer(t)
+	cfg := weatherAPIConfig(upstream.URL)
+	svc := newService(t, cfg)
+
+	metrics := handlers.NewHTTPMetrics()
+	mux := http.NewServeMux()
+	mux.Handle("/api/weather", metrics.Instrument("/api/weather")(handlers.WeatherHandler(svc)))
+	mux.Handle("/metrics", handlers.MetricsHandler(svc, metrics))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// un appel amont, un succès servi par le cache, un 400 du handler, puis
+	// une erreur du service
+	for _, query := range []string{"?city=Paris", "?city=Paris", ""} {
+		resp, err := http.Get(srv.URL + "/api/weather" + query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	_, _ = svc.GetWeatherForCity(context.Background(), "")
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("scraping: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	out := string(body)
+	for _, want := range []string{
+		"# TYPE weather_http_request_duration_seconds histogram",
+		`weather_http_requests_total{route="/api/weather",status="200"} 2`,
+		`weather_http_requests_total{route="/api/weather",status="400"} 1`,
+		`weather_http_request_duration_seconds_bucket{route="/api/weather",status="200",le="+Inf"} 2`,
+		`weather_http_request_duration_seconds_count{route="/api/weather",status="400"} 1`,
+		"weather_http_requests_in_flight 0",
+		`weather_upstream_requests_total{provider="weatherapi",status="200"} 1`,
+		`weather_upstream_request_duration_seconds_count{provider="weatherapi"} 1`,
+		`weather_upstream_breaker_state{provider="weatherapi",state="closed"} 1`,
+		`weather_errors_total{type="bad_request"} 1`,
+		`weather_cache_requests_total{result="hit"} 1`,
+		"weather_cache_hit_ratio 0.5",
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
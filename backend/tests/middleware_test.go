@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-app-backend/config"
+	"weather-app-backend/handlers"
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+	"weather-app-backend/utils"
+)
+
+// newBufferLogger retourne un journal au format format écrit dans un tampon.
+func newBufferLogger(format string) (*slog.Logger, *bytes.Buffer) {
+	var logs bytes.Buffer
+	return utils.NewLogger(&logs, format, slog.LevelDebug), &logs
+}
+
+// withMiddlewares applique la même chaîne que main.go.
+func withMiddlewares(h http.Handler, logger *slog.Logger) http.Handler {
+	return handlers.Chain(h, handlers.RequestID, handlers.AccessLog(logger), handlers.Recover)
+}
+
+func TestRecoverReturnsJSONAndLogsRequest(t *testing.T) {
+	logger, logs := newBufferLogger("text")
+	h := withMiddlewares(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/weather?city=Paris", nil)
+	req.Header.Set(handlers.RequestIDHeader, "req-42")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var body models.ErrorResponse
+	if rec.Code != http.StatusInternalServerError || json.Unmarshal(rec.Body.Bytes(), &body) != nil || body.Error == "" {
+		t.Fatalf("expected a JSON 500, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(handlers.RequestIDHeader) != "req-42" {
+		t.Fatalf("expected the request ID to be echoed, got %q", rec.Header().Get(handlers.RequestIDHeader))
+	}
+	out := logs.String()
+	if !strings.Contains(out, `msg="panic in handler"`) || !strings.Contains(out, "panic=boom") ||
+		!strings.Contains(out, "status=500") || !strings.Contains(out, "request_id=req-42") {
+		t.Fatalf("expected panic and access logs with the request ID, got:\n%s", out)
+	}
+}
+
+func TestRequestIDReachesServiceLogs(t *testing.T) {
+	logger, logs := newBufferLogger("json")
+	svc := services.NewWeatherServiceWithProvider(config.Default(), &fakeProvider{name: "fake", weather: &models.Weather{City: "Paris"}}, logger)
+	h := withMiddlewares(handlers.WeatherHandler(svc), logger)
+
+	// un identifiant invalide est remplacé
+	req := httptest.NewRequest(http.MethodGet, "/api/weather?city=Paris", nil)
+	req.Header.Set(handlers.RequestIDHeader, "bad id\nwith newline")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	id := rec.Header().Get(handlers.RequestIDHeader)
+	if rec.Code != http.StatusOK || !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(id) {
+		t.Fatalf("expected a generated request ID, got %d %q", rec.Code, id)
+	}
+
+	// une entrée JSON par ligne ; celle du service porte les champs de la requête
+	var served map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		if entry["msg"] == "weather served" {
+			served = entry
+		}
+	}
+	if served == nil || served["request_id"] != id || served["city"] != "Paris" || served["provider"] != "fake" || served["cache_hit"] != false {
+		t.Fatalf("expected the service logs to carry the request fields, got:\n%s", logs.String())
+	}
+}
+
+// slowProvider attend l'annulation du contexte.
+type slowProvider struct{ fakeProvider }
+
+func (s *slowProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRouteTimeoutReturns504(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.RouteTimeouts = map[string]time.Duration{"/api/weather": 20 * time.Millisecond}
+	svc := services.NewWeatherServiceWithProvider(cfg, &slowProvider{fakeProvider{name: "slow"}}, nil)
+	h := withMiddlewares(handlers.Timeout(svc, "/api/weather")(handlers.WeatherHandler(svc)), nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/weather?city=Paris", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d %q", rec.Code, rec.Body.String())
+	}
+}
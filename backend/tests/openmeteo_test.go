@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"weather-app-backend/config"
+	"weather-app-backend/services"
+)
+
+// useOpenMeteoFixture démarre un faux Open-Meteo servant les réponses
+// enregistrées et retourne un service configuré dessus.
+func useOpenMeteoFixture(t *testing.T) *services.WeatherService {
+	t.Helper()
+
+	fixture := func(name string) http.HandlerFunc {
+		body, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("reading fixture %s: %v", name, err)
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body)
+		}
+	}
+
+	geocoding := fixture("openmeteo_geocoding.json")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "Paris" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"generationtime_ms":0.1}`))
+			return
+		}
+		geocoding(w, r)
+	})
+	mux.HandleFunc("/v1/forecast", fixture("openmeteo_forecast.json"))
+	mux.HandleFunc("/v1/air-quality", fixture("openmeteo_air_quality.json"))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := config.Default()
+	cfg.Providers.Order = []string{config.ProviderOpenMeteo}
+	cfg.Providers.OpenMeteo = config.OpenMeteoConfig{
+		ForecastURL:   srv.URL + "/v1/forecast",
+		AirQualityURL: srv.URL + "/v1/air-quality",
+		GeocodingURL:  srv.URL + "/v1/search",
+	}
+	return newService(t, cfg)
+}
+
+func TestOpenMeteoProviderWithoutAPIKey(t *testing.T) {
+	svc := useOpenMeteoFixture(t)
+
+	w, err := svc.GetWeatherForCity(context.Background(), "Paris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.City != "Paris" || w.Country != "France" || w.Region != "Île-de-France" {
+		t.Fatalf("unexpected location: %s / %s / %s", w.City, w.Region, w.Country)
+	}
+	if w.Condition != "Partiellement nuageux" || w.WindDir != "SW" {
+		t.Fatalf("unexpected current conditions: %q wind=%q", w.Condition, w.WindDir)
+	}
+	if w.VisibilityKm != 24.1 || w.AirQualityIndex != 2 {
+		t.Fatalf("unexpected visibility=%v aqi=%v", w.VisibilityKm, w.AirQualityIndex)
+	}
+	if len(w.ForecastDays) != 3 || len(w.Hourly) != 24 {
+		t.Fatalf("expected 3 days / 24 hours, got %d / %d", len(w.ForecastDays), len(w.Hourly))
+	}
+
+	day := w.ForecastDays[1]
+	if !day.RiskThunder || day.Condition != "Orage" || day.ChanceOfRain != 85 {
+		t.Fatalf("unexpected day 2: %+v", day)
+	}
+	if day.Sunrise != "05:49 AM" || day.Sunset != "09:48 PM" {
+		t.Fatalf("unexpected astro times: %s / %s", day.Sunrise, day.Sunset)
+	}
+	if w.Hourly[13].Time != "2025-06-01 13:00" {
+		t.Fatalf("unexpected hourly time format: %s", w.Hourly[13].Time)
+	}
+}
+
+func TestOpenMeteoProviderUnknownCity(t *testing.T) {
+	svc := useOpenMeteoFixture(t)
+
+	_, err := svc.GetWeatherForCity(context.Background(), "Atlantide")
+	var werr *services.WeatherError
+	if !errors.As(err, &werr) || werr.Type != services.ErrTypeNotFound {
+		t.Fatalf("expected not_found error, got %v", err)
+	}
+}
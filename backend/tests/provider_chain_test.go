@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"weather-app-backend/config"
+	"weather-app-backend/handlers"
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+)
+
+func upstreamError() error {
+	return &services.WeatherError{Type: services.ErrTypeUpstream, Message: "erreur 5xx"}
+}
+
+func TestProviderChainFallsBackOnUpstreamError(t *testing.T) {
+	primary := &fakeProvider{name: "chain-primary", err: upstreamError()}
+	secondary := &fakeProvider{name: "chain-secondary", weather: &models.Weather{City: "Paris"}}
+	svc := newTestService(services.NewProviderChain(time.Minute, primary, secondary))
+
+	w, err := svc.GetWeatherForCity(context.Background(), "Paris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Provider != secondary.name {
+		t.Fatalf("expected response served by %s, got %q", secondary.name, w.Provider)
+	}
+
+	// Pendant le cooldown, le fournisseur en échec n'est plus sollicité.
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if primary.calls != 1 || secondary.calls != 2 {
+		t.Fatalf("expected primary=1 secondary=2 calls, got %d / %d", primary.calls, secondary.calls)
+	}
+
+	states, fallbacks := svc.ProvidersHealth()
+	if fallbacks != 1 {
+		t.Fatalf("expected one fallback, got %d", fallbacks)
+	}
+	for _, s := range states {
+		if s.Name == primary.name && (s.Healthy || s.LastError == "") {
+			t.Fatalf("expected %s to be unhealthy: %+v", primary.name, s)
+		}
+	}
+}
+
+func TestProviderChainDoesNotFallBackOnNotFound(t *testing.T) {
+	primary := &fakeProvider{
+		name: "chain-notfound",
+		err:  &services.WeatherError{Type: services.ErrTypeNotFound, Message: "introuvable"},
+	}
+	secondary := &fakeProvider{name: "chain-unused", weather: &models.Weather{City: "Paris"}}
+	svc := newTestService(services.NewProviderChain(time.Minute, primary, secondary))
+
+	_, err := svc.GetWeatherForCity(context.Background(), "Nowhere")
+	var werr *services.WeatherError
+	if !errors.As(err, &werr) || werr.Type != services.ErrTypeNotFound {
+		t.Fatalf("expected not_found error, got %v", err)
+	}
+	if secondary.calls != 0 {
+		t.Fatalf("secondary provider should not be called, got %d calls", secondary.calls)
+	}
+}
+
+func TestHealthHandlerReportsProviders(t *testing.T) {
+	cfg := weatherAPIConfig("http://127.0.0.1:1")
+	cfg.Providers.Order = []string{config.ProviderWeatherAPI, config.ProviderOpenMeteo}
+	svc := newService(t, cfg)
+
+	rec := httptest.NewRecorder()
+	handlers.HealthHandler(svc)(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	var body struct {
+		Status    string                    `json:"status"`
+		Providers []services.ProviderHealth `json:"providers"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding health: %v", err)
+	}
+	names := map[string]bool{}
+	for _, p := range body.Providers {
+		names[p.Name] = true
+	}
+	if !names["weatherapi"] || !names["openmeteo"] {
+		t.Fatalf("expected configured providers in health, got %+v", body.Providers)
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"weather-app-backend/config"
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+)
+
+// fakeProvider est un WeatherProvider en mémoire.
+type fakeProvider struct {
+	name    string
+	weather *models.Weather
+	err     error
+	calls   int
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) Current(ctx context.Context, location string) (*models.Weather, error) {
+	return f.Forecast(ctx, location, 1)
+}
+
+func (f *fakeProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	w := *f.weather
+	return &w, nil
+}
+
+func (f *fakeProvider) Alerts(ctx context.Context, location string) ([]services.WeatherAlert, error) {
+	return nil, f.err
+}
+
+// newTestService construit un service sur p avec la configuration par défaut.
+func newTestService(p services.WeatherProvider) *services.WeatherService {
+	return services.NewWeatherServiceWithProvider(config.Default(), p, nil)
+}
+
+func TestGetWeatherForCityUsesProvider(t *testing.T) {
+	fake := &fakeProvider{
+		name: "fake",
+		weather: &models.Weather{
+			City: "Lyon",
+			ForecastDays: []models.ForecastDay{
+				{Date: "2025-06-01", MaxTemp: 32},
+			},
+		},
+	}
+	svc := newTestService(fake)
+
+	w, err := svc.GetWeatherForCity(context.Background(), "Lyon")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.City != "Lyon" || fake.calls != 1 {
+		t.Fatalf("unexpected result city=%s calls=%d", w.City, fake.calls)
+	}
+	if len(w.Alerts) != 1 || w.Alerts[0].Type != "chaleur" {
+		t.Fatalf("expected heat alert, got %+v", w.Alerts)
+	}
+}
+
+func TestGetWeatherForCityPropagatesProviderError(t *testing.T) {
+	fake := &fakeProvider{
+		name: "fake",
+		err:  &services.WeatherError{Type: services.ErrTypeNotFound, Message: "introuvable"},
+	}
+	svc := newTestService(fake)
+
+	_, err := svc.GetWeatherForCity(context.Background(), "Nowhere")
+	var werr *services.WeatherError
+	if !errors.As(err, &werr) || werr.Type != services.ErrTypeNotFound {
+		t.Fatalf("expected not_found error, got %v", err)
+	}
+}
+
+func TestUnknownProviderIsConfigError(t *testing.T) {
+	cfg := config.Default()
+	cfg.Providers.Order = []string{"nope"}
+
+	_, err := services.NewWeatherService(cfg, nil)
+	var werr *services.WeatherError
+	if !errors.As(err, &werr) || werr.Type != services.ErrTypeConfig {
+		t.Fatalf("expected config error, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"weather-app-backend/handlers"
+	"weather-app-backend/services"
+	"weather-app-backend/utils"
+)
+
+func TestRateLimiterQueuesWithinDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	limiter := utils.NewRateLimiter("limited", nil, utils.RateLimitPolicy{RPS: 20, Burst: 1})
+	client := &http.Client{Transport: limiter}
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected the second call to wait for a token, took %s", elapsed)
+	}
+
+	// plus de jeton avant une échéance de 10 ms : échec immédiat
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	_, err := client.Do(req)
+	var rl *utils.RateLimitError
+	if !errors.As(err, &rl) || rl.RetryAfter <= 0 {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	if s := limiter.Stats(); s.Delayed != 1 || s.Rejected != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestRateLimitedServesStaleOrReturns503(t *testing.T) {
+	srv := newWeatherAPIFixtureServer(t)
+	cfg := weatherAPIConfig(srv.URL)
+	cfg.Providers.WeatherAPI.HTTP.RateLimit = 0.1 // un appel toutes les 10 s
+	cfg.Providers.WeatherAPI.HTTP.RateBurst = 1
+	cfg.Cache.TTLForecast = time.Millisecond
+	cfg.Cache.StaleWhileRevalidate = 0
+	svc := newService(t, cfg)
+
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	w, err := svc.GetWeatherForCity(ctx, "Paris")
+	if err != nil || !w.Stale {
+		t.Fatalf("expected a stale entry while rate limited, got %+v, %v", w, err)
+	}
+
+	_, err = svc.GetWeatherForCity(ctx, "Lyon")
+	var werr *services.WeatherError
+	if !errors.As(err, &werr) || werr.Type != services.ErrTypeRateLimited {
+		t.Fatalf("expected a rate limited error, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/weather?city=Lyon", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	handlers.WeatherHandler(svc)(rec, req)
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 503 with Retry-After, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
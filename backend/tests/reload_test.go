@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-app-backend/config"
+	"weather-app-backend/models"
+)
+
+// noEnv isole les tests de rechargement de l'environnement réel.
+func noEnv(string) (string, bool) { return "", false }
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+}
+
+const reloadBase = `
+providers:
+  order: [openmeteo]
+alerts:
+  default_city: Brest
+`
+
+func TestReloadSwapsThresholdsAndKeepsConfigOnInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, reloadBase)
+	opts := config.Options{File: path, Lookup: noEnv}
+
+	cfg, _, err := config.Load(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fake := &fakeProvider{name: "fake", weather: &models.Weather{
+		City:         "Brest",
+		ForecastDays: []models.ForecastDay{{Date: "2025-06-01", MaxTemp: 27}},
+	}}
+	svc := newTestService(fake)
+	reloader := config.NewReloader(opts, cfg, svc.Reload, nil)
+	if err := svc.Reload(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w, _ := svc.GetWeatherForCity(context.Background(), "Brest")
+	if len(w.Alerts) != 0 {
+		t.Fatalf("expected no alert at 27°C with default threshold, got %+v", w.Alerts)
+	}
+
+	writeConfig(t, path, reloadBase+"  heat_c: 25\n")
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("unexpected reload error: %v", err)
+	}
+	w, _ = svc.GetWeatherForCity(context.Background(), "Brest")
+	if len(w.Alerts) != 1 || w.Alerts[0].Type != "chaleur" {
+		t.Fatalf("expected heat alert after reload, got %+v", w.Alerts)
+	}
+
+	writeConfig(t, path, reloadBase+"  heat_c: 20\n  rain_chance: 150\n")
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("expected invalid reload to fail")
+	}
+	if got := svc.Config().Alerts.HeatC; got != 25 || reloader.Current().Alerts.HeatC != 25 {
+		t.Fatalf("expected previous config to stay active, got heat_c=%v", got)
+	}
+}
+
+func TestReloadSwapsProviders(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "weatherapi_forecast.json"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	serve := func(hits *atomic.Int32) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			_, _ = w.Write(body)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	var hitsA, hitsB atomic.Int32
+	a, b := serve(&hitsA), serve(&hitsB)
+
+	cfgA, cfgB := weatherAPIConfig(a.URL), weatherAPIConfig(b.URL)
+	cfgA.Cache.Enabled, cfgB.Cache.Enabled = false, false
+
+	svc := newService(t, cfgA)
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Reload(cfgB); err != nil {
+		t.Fatalf("unexpected reload error: %v", err)
+	}
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hitsA.Load() != 1 || hitsB.Load() != 1 {
+		t.Fatalf("expected one call per provider, got a=%d b=%d", hitsA.Load(), hitsB.Load())
+	}
+}
+
+func TestReloaderWatchesConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, reloadBase)
+	opts := config.Options{File: path, Lookup: noEnv}
+
+	cfg, _, err := config.Load(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc := newTestService(&fakeProvider{name: "fake"})
+	reloader := config.NewReloader(opts, cfg, svc.Reload, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Run(ctx, 10*time.Millisecond)
+
+	writeConfig(t, path, reloadBase+"  rain_chance: 40\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for svc.Config().Alerts.RainChance != 40 {
+		if time.Now().After(deadline) {
+			t.Fatal("config change was not picked up by the watcher")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
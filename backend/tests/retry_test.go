@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-app-backend/utils"
+)
+
+// newFlakyServer répond failures fois avec status (et Retry-After s'il est
+// défini), puis 200.
+func newFlakyServer(t *testing.T, failures int32, status int, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestRetryTransportRecoversFromTransientErrors(t *testing.T) {
+	srv, calls := newFlakyServer(t, 2, http.StatusServiceUnavailable, "")
+
+	upstreams := utils.NewUpstreams()
+	client := upstreams.Client("flaky", utils.UpstreamOptions{
+		Timeout: 5 * time.Second,
+		Retry:   utils.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+	})
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("expected 200 after 3 calls, got %d after %d", resp.StatusCode, calls.Load())
+	}
+
+	stats := upstreams.Stats()
+	if len(stats) != 1 || stats[0].Name != "flaky" || stats[0].Requests != 1 || stats[0].Attempts != 3 || stats[0].Retries != 2 || stats[0].Exhausted != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestRetryTransportGivesUpAndSkipsNonIdempotent(t *testing.T) {
+	srv, calls := newFlakyServer(t, 100, http.StatusBadGateway, "")
+	transport := utils.NewRetryTransport("down", nil, utils.RetryPolicy{MaxRetries: 2, MaxDelay: time.Millisecond})
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 3 {
+		t.Fatalf("expected the last 502 after 3 calls, got %d after %d", resp.StatusCode, calls.Load())
+	}
+
+	resp, err = client.Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if calls.Load() != 4 {
+		t.Fatalf("POST must not be retried, got %d calls", calls.Load())
+	}
+	if s := transport.Stats(); s.Retries != 2 || s.Exhausted != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestRetryTransportHonoursRetryAfterAndDeadline(t *testing.T) {
+	policy := utils.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}
+
+	// Retry-After: 1 l'emporte sur le backoff de 1 ms
+	srv, calls := newFlakyServer(t, 1, http.StatusTooManyRequests, "1")
+	client := &http.Client{Transport: utils.NewRetryTransport("limited", nil, policy)}
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); resp.StatusCode != http.StatusOK || elapsed < 900*time.Millisecond {
+		t.Fatalf("expected 200 after waiting Retry-After, got %d after %s (%d calls)", resp.StatusCode, elapsed, calls.Load())
+	}
+
+	// une attente qui dépasserait l'échéance du contexte n'est pas tentée
+	srv, calls = newFlakyServer(t, 100, http.StatusServiceUnavailable, "3")
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	start = time.Now()
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 || elapsed > 400*time.Millisecond {
+		t.Fatalf("expected an immediate 503 within the deadline, got %d after %s (%d calls)", resp.StatusCode, elapsed, calls.Load())
+	}
+	// un Retry-After plus long que MaxDelay n'est pas raccourci : pas de
+	// nouvelle tentative
+	srv, calls = newFlakyServer(t, 100, http.StatusTooManyRequests, "120")
+	start = time.Now()
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 || elapsed > time.Second {
+		t.Fatalf("expected an immediate 429 when Retry-After exceeds MaxDelay, got %d after %s (%d calls)", resp.StatusCode, elapsed, calls.Load())
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"weather-app-backend/handlers"
+	"weather-app-backend/models"
+)
+
+func TestVersionedRoutesAndDeprecatedAliases(t *testing.T) {
+	svc := useWeatherAPIFixture(t)
+	since := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+	successor := func(r *http.Request) string { return "/api/v1/weather/" + r.URL.Query().Get("city") }
+
+	// même table que main.go : alias et front sans méthode, 405 limité à /api/v1
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/weather/{location}", handlers.WeatherHandler(svc))
+	mux.Handle("/api/v1/", handlers.MethodNotAllowed(mux, "/api/v1/"))
+	mux.Handle("/api/weather", handlers.Deprecated(since, sunset, successor)(handlers.WeatherHandler(svc)))
+	mux.Handle("/", handlers.Frontend(t.TempDir()))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// le lieu est lu dans le chemin
+	resp, err := http.Get(srv.URL + "/api/v1/weather/Paris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var w models.Weather
+	_ = json.NewDecoder(resp.Body).Decode(&w)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || w.City != "Paris" || resp.Header.Get("Deprecation") != "" {
+		t.Fatalf("unexpected v1 response %d %+v %v", resp.StatusCode, w, resp.Header)
+	}
+
+	resp, err = http.Post(srv.URL+"/api/v1/weather/Paris", "application/json", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != "GET, HEAD" {
+		t.Fatalf("expected 405 with Allow, got %d %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+
+	// hors des routes /api/v1 connues, une autre méthode reçoit 404
+	for _, path := range []string{"/api/v1/unknown", "/foo", "/"} {
+		resp, err = http.Post(srv.URL+path, "application/json", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound || resp.Header.Get("Allow") != "" {
+			t.Fatalf("POST %s: expected 404 without Allow, got %d %q", path, resp.StatusCode, resp.Header.Get("Allow"))
+		}
+	}
+
+	// l'ancienne route répond de même, quelle que soit la méthode, avec les
+	// en-têtes de dépréciation
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req, _ := http.NewRequest(method, srv.URL+"/api/weather?city=Paris", nil)
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		for header, want := range map[string]string{
+			"Deprecation": "@1792195200",
+			"Sunset":      "Fri, 30 Apr 2027 00:00:00 GMT",
+			"Link":        `</api/v1/weather/Paris>; rel="successor-version"`,
+		} {
+			if got := resp.Header.Get(header); resp.StatusCode != http.StatusOK || got != want {
+				t.Fatalf("legacy %s: expected %s %q, got %d %q", method, header, want, resp.StatusCode, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-app-backend/services"
+)
+
+func TestShutdownCancelsPendingRevalidation(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "weatherapi_forecast.json"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	// premier appel servi, les suivants bloqués jusqu'à l'abandon du client
+	var calls atomic.Int32
+	canceled := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body)
+			return
+		}
+		<-r.Context().Done()
+		close(canceled)
+	}))
+	defer srv.Close()
+
+	cfg := weatherAPIConfig(srv.URL)
+	cfg.Cache.TTLForecast = time.Millisecond
+	cfg.Cache.StaleWhileRevalidate = time.Minute
+	cfg.Providers.WeatherAPI.HTTP.MaxRetries = 0
+	svc := newService(t, cfg)
+
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if w, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil || !w.Stale {
+		t.Fatalf("expected a stale entry to trigger a refresh, got %+v, %v", w, err)
+	}
+	for calls.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := svc.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the pending refresh to exceed the deadline, got %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the pending refresh to be canceled")
+	}
+
+	// après l'arrêt, une entrée périmée ne relance plus de rafraîchissement
+	if _, err := svc.GetWeatherForCity(context.Background(), "Paris"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected no refresh after shutdown, got %d upstream calls", n)
+	}
+}
+
+func TestDiskCacheFlushKeepsAccessOrder(t *testing.T) {
+	dir := t.TempDir()
+	c, err := services.NewDiskCache(dir, 1<<20, nil)
+	if err != nil {
+		t.Fatalf("opening disk cache: %v", err)
+	}
+	c.Set("old", freshEntry(`{"city":"Brest"}`, time.Hour))
+	time.Sleep(10 * time.Millisecond)
+	c.Set("new", freshEntry(`{"city":"Nice"}`, time.Hour))
+	time.Sleep(10 * time.Millisecond)
+	c.Get("old") // "old" devient la plus récemment lue
+	if err := c.Flush(); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+
+	// au redémarrage, une taille à peine réduite évince la moins récemment lue
+	reopened, err := services.NewDiskCache(dir, c.Stats().Bytes-1, nil)
+	if err != nil {
+		t.Fatalf("reopening disk cache: %v", err)
+	}
+	if _, ok := reopened.Get("old"); !ok {
+		t.Fatal("expected the recently read entry to survive")
+	}
+	if _, ok := reopened.Get("new"); ok {
+		t.Fatal("expected the least recently read entry to be evicted")
+	}
+}
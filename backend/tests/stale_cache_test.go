@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"weather-app-backend/models"
+	"weather-app-backend/services"
+)
+
+// switchProvider renvoie une température qui évolue et peut tomber en panne.
+type switchProvider struct {
+	mu    sync.Mutex
+	temp  float64
+	down  bool
+	calls int
+}
+
+func (s *switchProvider) Name() string { return "switch" }
+
+func (s *switchProvider) Current(ctx context.Context, location string) (*models.Weather, error) {
+	return s.Forecast(ctx, location, 1)
+}
+
+func (s *switchProvider) Forecast(ctx context.Context, location string, days int) (*models.Weather, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.down {
+		return nil, upstreamError()
+	}
+	return &models.Weather{City: location, Temperature: s.temp}, nil
+}
+
+func (s *switchProvider) Alerts(ctx context.Context, location string) ([]services.WeatherAlert, error) {
+	return nil, nil
+}
+
+func (s *switchProvider) set(temp float64, down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.temp, s.down = temp, down
+}
+
+func (s *switchProvider) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	inner := &switchProvider{temp: 10}
+	ttls := services.CacheTTLs{Forecast: 50 * time.Millisecond, StaleWhileRevalidate: time.Minute}
+	svc := newTestService(services.NewCachedProvider(inner, services.NewMemoryCache(10), ttls))
+
+	if _, err := svc.GetWeatherForCity(context.Background(), "Brest"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inner.set(12, false)
+	time.Sleep(80 * time.Millisecond)
+
+	// Entrée expirée mais dans la fenêtre de grâce : servie immédiatement.
+	w, err := svc.GetWeatherForCity(context.Background(), "Brest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !w.Cached || !w.Stale || w.Temperature != 10 {
+		t.Fatalf("expected stale cached value 10, got cached=%v stale=%v temp=%v", w.Cached, w.Stale, w.Temperature)
+	}
+
+	// Le rafraîchissement en arrière-plan met à jour l'entrée.
+	deadline := time.Now().Add(time.Second)
+	for {
+		w, err = svc.GetWeatherForCity(context.Background(), "Brest")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if w.Temperature == 12 && !w.Stale {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("entry was not revalidated, last temp=%v stale=%v", w.Temperature, w.Stale)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := inner.callCount(); got != 2 {
+		t.Fatalf("expected 2 upstream calls, got %d", got)
+	}
+}
+
+func TestServeStaleOnUpstreamError(t *testing.T) {
+	inner := &switchProvider{temp: 8}
+	ttls := services.CacheTTLs{Forecast: 20 * time.Millisecond, StaleIfError: time.Hour}
+	svc := newTestService(services.NewCachedProvider(inner, services.NewMemoryCache(10), ttls))
+
+	if _, err := svc.GetWeatherForCity(context.Background(), "Rennes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inner.set(0, true)
+	time.Sleep(40 * time.Millisecond)
+
+	w, err := svc.GetWeatherForCity(context.Background(), "Rennes")
+	if err != nil {
+		t.Fatalf("expected stale data instead of error, got %v", err)
+	}
+	if !w.Stale || !w.Cached || w.Temperature != 8 || w.CacheAgeSeconds < 0 {
+		t.Fatalf("unexpected stale response: %+v", w)
+	}
+
+	// Sans entrée en cache, la panne remonte normalement.
+	if _, err := svc.GetWeatherForCity(context.Background(), "Quimper"); err == nil {
+		t.Fatalf("expected upstream error for uncached city")
+	}
+}
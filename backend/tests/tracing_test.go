@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"weather-app-backend/handlers"
+	"weather-app-backend/utils"
+)
+
+// newRecordingProvider crée un fournisseur de traceurs qui garde les spans
+// terminés en mémoire.
+func newRecordingProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp, recorder
+}
+
+func TestTracingFromHandlerToUpstream(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "weatherapi_forecast.json"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	var received string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer upstream.Close()
+
+	tp, recorder := newRecordingProvider(t)
+	svc := newService(t, weatherAPIConfig(upstream.URL))
+	h := handlers.Trace(tp, "/api/weather")(handlers.WeatherHandler(svc))
+
+	const incoming = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodGet, "/api/weather?city=Paris", nil)
+	req.Header.Set("traceparent", incoming)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		if s.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Fatalf("span %q is not part of the incoming trace", s.Name())
+		}
+		spans[s.Name()] = s
+	}
+
+	// chaque span est l'enfant du précédent ; le premier, de l'appelant
+	parent := "00f067aa0ba902b7"
+	for _, name := range []string{"GET /api/weather", "WeatherService.GetWeatherForCity", "weatherapi.fetch", "HTTP GET"} {
+		s, ok := spans[name]
+		if !ok {
+			t.Fatalf("missing span %q, got %d spans", name, len(spans))
+		}
+		if s.Parent().SpanID().String() != parent {
+			t.Fatalf("span %q: expected parent %s, got %s", name, parent, s.Parent().SpanID())
+		}
+		parent = s.SpanContext().SpanID().String()
+	}
+	if spans["GET /api/weather"].SpanKind() != trace.SpanKindServer || spans["HTTP GET"].SpanKind() != trace.SpanKindClient {
+		t.Fatalf("unexpected span kinds")
+	}
+	if spans["weatherapi.decode"].Parent().SpanID() != spans["weatherapi.fetch"].SpanContext().SpanID() {
+		t.Fatalf("expected the decode span under the fetch span")
+	}
+	if spans["deriveAlerts"].Parent().SpanID() != spans["WeatherService.GetWeatherForCity"].SpanContext().SpanID() {
+		t.Fatalf("expected the deriveAlerts span under the service span")
+	}
+
+	// le fournisseur reçoit le contexte du span client
+	sc := spans["HTTP GET"].SpanContext()
+	if want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"; received != want {
+		t.Fatalf("expected upstream traceparent %q, got %q", want, received)
+	}
+}
+
+func TestTraceHonoursIncomingSamplingDecision(t *testing.T) {
+	for _, tc := range []struct {
+		name, header string
+		spans        int
+		newTrace     bool
+	}{
+		{"invalid header starts a new trace", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", 1, true},
+		{"unsampled caller", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())))
+			recorder := tracetest.NewSpanRecorder()
+			tp.RegisterSpanProcessor(recorder)
+			defer tp.Shutdown(context.Background())
+
+			h := handlers.Trace(tp, "/api/health")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+			req.Header.Set("traceparent", tc.header)
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			if len(spans) != tc.spans {
+				t.Fatalf("expected %d spans, got %d", tc.spans, len(spans))
+			}
+			if tc.newTrace && (spans[0].Parent().IsValid() || spans[0].SpanContext().TraceID().String() == "4bf92f3577b34da6a3ce929d0e0e4736") {
+				t.Fatalf("expected a new root span, got parent %v", spans[0].Parent())
+			}
+		})
+	}
+}
+
+func TestOTLPExportToCollector(t *testing.T) {
+	var got coltracepb.ExportTraceServiceRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/x-protobuf" || proto.Unmarshal(body, &got) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer collector.Close()
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(collector.URL+"/v1/traces"))
+	if err != nil {
+		t.Fatalf("creating exporter: %v", err)
+	}
+	tp := utils.NewTracerProvider(exporter, "weather-test", 1, nil)
+	_, span := tp.Tracer(utils.TracerName).Start(context.Background(), "GET /api/weather", trace.WithSpanKind(trace.SpanKindServer))
+	utils.SpanError(span, errors.New("calling https://api.weatherapi.com/v1/forecast.json?key=secret123: boom"))
+	span.End()
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rs := got.GetResourceSpans()
+	if len(rs) != 1 || len(rs[0].GetScopeSpans()) != 1 || len(rs[0].GetScopeSpans()[0].GetSpans()) != 1 {
+		t.Fatalf("unexpected payload %v", &got)
+	}
+	var service string
+	for _, a := range rs[0].GetResource().GetAttributes() {
+		if a.GetKey() == "service.name" {
+			service = a.GetValue().GetStringValue()
+		}
+	}
+	s := rs[0].GetScopeSpans()[0].GetSpans()[0]
+	if service != "weather-test" || s.GetName() != "GET /api/weather" || s.GetKind() != tracepb.Span_SPAN_KIND_SERVER ||
+		s.GetStatus().GetCode() != tracepb.Status_STATUS_CODE_ERROR || len(s.GetTraceId()) != 16 {
+		t.Fatalf("unexpected payload %v", &got)
+	}
+	if msg := s.GetStatus().GetMessage(); msg == "" || strings.Contains(msg, "secret123") {
+		t.Fatalf("expected a redacted status message, got %q", msg)
+	}
+}
@@ -2,18 +2,121 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"weather-app-backend/config"
+	"weather-app-backend/handlers"
+	"weather-app-backend/models"
 	"weather-app-backend/services"
 )
 
+// newWeatherAPIFixtureServer sert la réponse forecast.json enregistrée.
+func newWeatherAPIFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "weatherapi_forecast.json"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// weatherAPIConfig retourne une configuration WeatherAPI pointant sur url.
+func weatherAPIConfig(url string) *config.Config {
+	cfg := config.Default()
+	cfg.Providers.Order = []string{config.ProviderWeatherAPI}
+	cfg.Providers.WeatherAPI.APIKey = "test-key"
+	cfg.Providers.WeatherAPI.URL = url + "/v1/forecast.json"
+	return cfg
+}
+
+// newService construit un WeatherService à partir de cfg.
+func newService(t *testing.T, cfg *config.Config) *services.WeatherService {
+	t.Helper()
+	svc, err := services.NewWeatherService(cfg, nil)
+	if err != nil {
+		t.Fatalf("building service: %v", err)
+	}
+	return svc
+}
+
+// useWeatherAPIFixture construit un service WeatherAPI sur le serveur local.
+func useWeatherAPIFixture(t *testing.T) *services.WeatherService {
+	t.Helper()
+	srv := newWeatherAPIFixtureServer(t)
+	return newService(t, weatherAPIConfig(srv.URL))
+}
+
 func TestGetWeatherForCity(t *testing.T) {
+	svc := useWeatherAPIFixture(t)
+
 	ctx := context.Background()
-	w, err := services.GetWeatherForCity(ctx, "Paris")
+	w, err := svc.GetWeatherForCity(ctx, "Paris")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if w.City != "Paris" {
 		t.Fatalf("expected city Paris, got %s", w.City)
 	}
+	if len(w.ForecastDays) != 3 {
+		t.Fatalf("expected 3 forecast days, got %d", len(w.ForecastDays))
+	}
+	if !w.ForecastDays[1].RiskThunder {
+		t.Fatalf("expected thunder risk on day 2")
+	}
+	if len(w.Alerts) == 0 {
+		t.Fatalf("expected derived alerts")
+	}
+}
+
+func TestGetGlobalWeatherAlerts(t *testing.T) {
+	svc := useWeatherAPIFixture(t)
+
+	alerts, err := svc.GetGlobalWeatherAlerts(context.Background(), "Paris")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Event != "Orages" {
+		t.Fatalf("unexpected alerts: %+v", alerts)
+	}
+}
+
+func TestAlertsHandlerMapsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+	}{
+		{&services.WeatherError{Type: services.ErrTypeNotFound, Message: "no matching location", Cause: errors.New("upstream 1006")}, http.StatusNotFound},
+		{&services.WeatherError{Type: services.ErrTypeBadRequest, Message: "invalid query", Cause: errors.New("upstream 400")}, http.StatusBadRequest},
+		{&services.WeatherError{Type: services.ErrTypeQuota, Message: "quota exceeded", Cause: errors.New("upstream 403")}, http.StatusServiceUnavailable},
+		{&services.WeatherError{Type: services.ErrTypeUpstream, Message: "bad gateway", Cause: errors.New("dial tcp 10.0.0.1:443")}, http.StatusBadGateway},
+		{errors.New("internal failure"), http.StatusInternalServerError},
+	} {
+		svc := newTestService(&fakeProvider{name: "fake", err: tc.err})
+		rec := httptest.NewRecorder()
+		handlers.AlertsHandler(svc)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/alerts/Paris", nil))
+
+		var body models.ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%v: expected a JSON error, got %v", tc.err, err)
+		}
+		if rec.Code != tc.status || body.Error == "" || strings.Contains(body.Error, tc.err.Error()) {
+			t.Fatalf("%v: expected %d without the internal message, got %d %q", tc.err, tc.status, rec.Code, body.Error)
+		}
+	}
 }
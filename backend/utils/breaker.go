@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// États d'un disjoncteur.
+const (
+	BreakerClosed   = "closed"    // appels normaux
+	BreakerOpen     = "open"      // appels refusés immédiatement
+	BreakerHalfOpen = "half_open" // quelques appels d'essai
+	BreakerDisabled = "disabled"  // Threshold nul
+)
+
+// ErrCircuitOpen est retournée (enveloppée dans *CircuitOpenError) quand le
+// disjoncteur refuse un appel.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitOpenError précise le fournisseur et la date de la prochaine tentative.
+type CircuitOpenError struct {
+	Provider string
+	RetryAt  time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %v until %s", e.Provider, ErrCircuitOpen, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Unwrap() error { return ErrCircuitOpen }
+
+// BreakerPolicy configure un disjoncteur.
+type BreakerPolicy struct {
+	Threshold      int           // échecs consécutifs avant ouverture (0 = désactivé)
+	OpenTimeout    time.Duration // durée d'ouverture avant les appels d'essai
+	HalfOpenProbes int           // appels d'essai simultanés en demi-ouverture
+}
+
+// Breaker est un disjoncteur (fermé / ouvert / demi-ouvert) autour d'un
+// transport HTTP. Une erreur réseau ou une réponse 5xx compte comme un
+// échec ; après Threshold échecs consécutifs, les appels échouent
+// immédiatement pendant OpenTimeout, puis quelques appels d'essai décident
+// de la fermeture ou d'une nouvelle ouverture.
+type Breaker struct {
+	name string
+	next http.RoundTripper
+	now  func() time.Time
+
+	mu       sync.Mutex
+	policy   BreakerPolicy
+	state    string
+	failures int       // échecs consécutifs
+	openedAt time.Time // dernière ouverture
+	probes   int       // appels d'essai en cours
+	opens    uint64
+	rejected uint64
+}
+
+// NewBreaker enveloppe next (http.DefaultTransport si nil) ; name identifie
+// le fournisseur dans les journaux.
+func NewBreaker(name string, next http.RoundTripper, policy BreakerPolicy) *Breaker {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Breaker{name: name, next: next, now: time.Now, policy: policy, state: BreakerClosed}
+}
+
+// SetPolicy remplace la politique (rechargement de configuration) ; l'état
+// courant est conservé.
+func (b *Breaker) SetPolicy(p BreakerPolicy) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.policy = p
+	if p.Threshold <= 0 {
+		b.state, b.failures, b.probes = BreakerClosed, 0, 0
+	}
+}
+
+func (b *Breaker) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	probe, err := b.allow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.next.RoundTrip(req)
+	b.record(ctx, probe, b.outcome(ctx, resp, err))
+	return resp, err
+}
+
+// allow décide si un appel peut partir ; probe indique un appel d'essai.
+func (b *Breaker) allow(ctx context.Context) (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.policy.Threshold <= 0 {
+		return false, nil
+	}
+	if b.state == BreakerOpen {
+		retryAt := b.openedAt.Add(b.policy.OpenTimeout)
+		if b.now().Before(retryAt) {
+			b.rejected++
+			return false, &CircuitOpenError{Provider: b.name, RetryAt: retryAt}
+		}
+		b.state = BreakerHalfOpen
+		LoggerFrom(ctx).InfoContext(ctx, "circuit half-open, probing", "provider", b.name)
+	}
+	if b.state == BreakerHalfOpen {
+		if b.probes >= max(b.policy.HalfOpenProbes, 1) {
+			b.rejected++
+			return false, &CircuitOpenError{Provider: b.name, RetryAt: b.now().Add(time.Second)}
+		}
+		b.probes++
+		return true, nil
+	}
+	return false, nil
+}
+
+// callOutcome est le verdict d'un appel pour le disjoncteur.
+type callOutcome int
+
+const (
+	callSucceeded callOutcome = iota
+	callFailed
+	callIgnored // ni succès ni échec : l'état et le compte d'échecs sont inchangés
+)
+
+// record met à jour l'état après un appel.
+func (b *Breaker) record(ctx context.Context, probe bool, outcome callOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probes--
+	}
+	if b.policy.Threshold <= 0 || outcome == callIgnored {
+		return
+	}
+	if outcome == callSucceeded {
+		if b.state != BreakerClosed {
+			LoggerFrom(ctx).InfoContext(ctx, "circuit closed", "provider", b.name)
+		}
+		b.state, b.failures = BreakerClosed, 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.policy.Threshold) {
+		b.state, b.openedAt = BreakerOpen, b.now()
+		b.opens++
+		LoggerFrom(ctx).WarnContext(ctx, "circuit open", "provider", b.name, "consecutive_failures", b.failures, "open_timeout", b.policy.OpenTimeout)
+	}
+}
+
+// outcome classe l'appel : une erreur réseau ou une réponse 5xx est un
+// échec ; une annulation par l'appelant ou un refus de la limite de débit
+// sortante ne disent rien du fournisseur et sont ignorés (un appel d'essai
+// ignoré laisse le disjoncteur demi-ouvert).
+func (b *Breaker) outcome(ctx context.Context, resp *http.Response, err error) callOutcome {
+	switch {
+	case err != nil && (ctx.Err() != nil || errors.Is(err, ErrRateLimited)):
+		return callIgnored
+	case err != nil || resp.StatusCode >= 500:
+		return callFailed
+	default:
+		return callSucceeded
+	}
+}
+
+// BreakerStats résume l'état d'un disjoncteur.
+type BreakerStats struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Opens               uint64     `json:"opens"`    // ouvertures depuis le démarrage
+	Rejected            uint64     `json:"rejected"` // appels refusés sans être émis
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// Stats retourne l'état du disjoncteur.
+func (b *Breaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := BreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Opens:               b.opens,
+		Rejected:            b.rejected,
+	}
+	if b.policy.Threshold <= 0 {
+		s.State = BreakerDisabled
+	}
+	if b.state == BreakerOpen {
+		at := b.openedAt.Add(b.policy.OpenTimeout)
+		s.RetryAt = &at
+	}
+	return s
+}
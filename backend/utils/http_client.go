@@ -1,14 +1,167 @@
 package utils
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
-var defaultClient = &http.Client{
-	Timeout: 5 * time.Second,
+// UpstreamOptions configure le client HTTP d'un fournisseur.
+type UpstreamOptions struct {
+	Timeout   time.Duration
+	Retry     RetryPolicy
+	Breaker   BreakerPolicy
+	RateLimit RateLimitPolicy
+}
+
+// UpstreamStats est l'activité HTTP d'un fournisseur.
+type UpstreamStats struct {
+	Name string `json:"name"`
+	RetryStats
+	Breaker   BreakerStats   `json:"breaker"`
+	RateLimit RateLimitStats `json:"rate_limit"`
+	// Responses compte les tentatives envoyées par statut HTTP ("200",
+	// "503"…) ou "error" (pas de réponse) ; Latency est leur durée jusqu'à
+	// la réception des en-têtes.
+	Responses map[string]uint64 `json:"responses,omitempty"`
+	Latency   HistogramSnapshot `json:"-"`
+}
+
+// Upstreams fournit un client HTTP par fournisseur : disjoncteur, puis
+// nouvelles tentatives, puis limite de débit (chaque tentative consomme un
+// jeton), puis un span et une mesure par tentative envoyée. Les transports
+// (et donc leur état et leurs compteurs) sont conservés d'un rechargement à
+// l'autre.
+type Upstreams struct {
+	mu        sync.Mutex
+	upstreams map[string]*upstream
+}
+
+// upstream regroupe les transports d'un fournisseur.
+type upstream struct {
+	meter   *upstreamMeter
+	limiter *RateLimiter
+	retry   *RetryTransport
+	breaker *Breaker
+}
+
+// NewUpstreams crée un registre vide.
+func NewUpstreams() *Upstreams {
+	return &Upstreams{upstreams: make(map[string]*upstream)}
+}
+
+// Client retourne un client HTTP pour le fournisseur name, avec les
+// options données (qui remplacent celles d'un appel précédent).
+func (u *Upstreams) Client(name string, opts UpstreamOptions) *http.Client {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	up, ok := u.upstreams[name]
+	if !ok {
+		meter := newUpstreamMeter(http.DefaultTransport)
+		limiter := NewRateLimiter(name, tracingTransport{name: name, next: meter}, opts.RateLimit)
+		retry := NewRetryTransport(name, limiter, opts.Retry)
+		up = &upstream{meter: meter, limiter: limiter, retry: retry, breaker: NewBreaker(name, retry, opts.Breaker)}
+		u.upstreams[name] = up
+	} else {
+		up.limiter.SetPolicy(opts.RateLimit)
+		up.retry.SetPolicy(opts.Retry)
+		up.breaker.SetPolicy(opts.Breaker)
+	}
+	return &http.Client{Timeout: opts.Timeout, Transport: up.breaker}
+}
+
+// Stats retourne l'activité de chaque fournisseur, triée par nom.
+func (u *Upstreams) Stats() []UpstreamStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stats := make([]UpstreamStats, 0, len(u.upstreams))
+	for name, up := range u.upstreams {
+		s := UpstreamStats{Name: name, RetryStats: up.retry.Stats(), Breaker: up.breaker.Stats(), RateLimit: up.limiter.Stats()}
+		s.Responses, s.Latency = up.meter.stats()
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// tracingTransport ouvre un span client par tentative (avec le fournisseur
+// du span de la requête) et le propage au fournisseur par traceparent.
+type tracingTransport struct {
+	name string
+	next http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracerFrom(req.Context()).Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("provider", t.name),
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path), // jamais la requête : elle porte la clé API
+		),
+	)
+	defer span.End()
+
+	if span.SpanContext().IsValid() {
+		req = req.Clone(ctx)
+		Propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		SpanError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		SpanError(span, fmt.Errorf("upstream answered %s", resp.Status))
+	}
+	return resp, nil
+}
+
+// upstreamMeter mesure chaque tentative réellement envoyée : statut et durée.
+type upstreamMeter struct {
+	next    http.RoundTripper
+	latency *Histogram
+
+	mu        sync.Mutex
+	responses map[string]uint64
+}
+
+func newUpstreamMeter(next http.RoundTripper) *upstreamMeter {
+	return &upstreamMeter{next: next, latency: NewHistogram(LatencyBuckets), responses: make(map[string]uint64)}
+}
+
+func (m *upstreamMeter) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := m.next.RoundTrip(req)
+	m.latency.ObserveDuration(time.Since(start))
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	m.mu.Lock()
+	m.responses[status]++
+	m.mu.Unlock()
+	return resp, err
 }
 
-func HTTPClient() *http.Client {
-	return defaultClient
+func (m *upstreamMeter) stats() (map[string]uint64, HistogramSnapshot) {
+	m.mu.Lock()
+	responses := make(map[string]uint64, len(m.responses))
+	for status, n := range m.responses {
+		responses[status] = n
+	}
+	m.mu.Unlock()
+	return responses, m.latency.Snapshot()
 }
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets sont les bornes (en secondes) des histogrammes de durée.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram compte des observations par intervalle, à la manière d'un
+// histogramme Prometheus.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64 // par intervalle, non cumulés ; le dernier est +Inf
+	sum     float64
+}
+
+// NewHistogram crée un histogramme aux bornes croissantes buckets.
+func NewHistogram(buckets []float64) *Histogram {
+	return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets)+1)}
+}
+
+// Observe ajoute une observation.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += v
+	h.mu.Unlock()
+}
+
+// ObserveDuration ajoute une durée, en secondes.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// Snapshot retourne l'état courant (compteurs cumulés).
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := HistogramSnapshot{Buckets: h.buckets, Counts: make([]uint64, len(h.counts)), Sum: h.sum}
+	for i, c := range h.counts {
+		s.Count += c
+		s.Counts[i] = s.Count
+	}
+	return s
+}
+
+// HistogramSnapshot est l'état d'un histogramme : Counts[i] est le nombre
+// d'observations ≤ Buckets[i], le dernier élément le total (+Inf).
+type HistogramSnapshot struct {
+	Buckets []float64
+	Counts  []uint64
+	Sum     float64
+	Count   uint64
+}
+
+// Label est une étiquette d'une série.
+type Label struct {
+	Name, Value string
+}
+
+// MetricsWriter écrit des séries au format texte de Prometheus (0.0.4).
+// La première erreur d'écriture est conservée et retournée par Flush.
+type MetricsWriter struct {
+	w *bufio.Writer
+}
+
+// NewMetricsWriter écrit vers w.
+func NewMetricsWriter(w io.Writer) *MetricsWriter {
+	return &MetricsWriter{w: bufio.NewWriter(w)}
+}
+
+// Family ouvre une famille de séries : typ vaut counter, gauge ou histogram.
+func (m *MetricsWriter) Family(name, typ, help string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, typ)
+}
+
+// Sample écrit une valeur.
+func (m *MetricsWriter) Sample(name string, value float64, labels ...Label) {
+	m.w.WriteString(name)
+	writeLabels(m.w, labels)
+	m.w.WriteByte(' ')
+	m.w.WriteString(formatFloat(value))
+	m.w.WriteByte('\n')
+}
+
+// Histogram écrit les séries _bucket, _sum et _count d'un histogramme.
+func (m *MetricsWriter) Histogram(name string, s HistogramSnapshot, labels ...Label) {
+	bucketLabels := append(append([]Label(nil), labels...), Label{Name: "le"})
+	for i, count := range s.Counts {
+		le := "+Inf"
+		if i < len(s.Buckets) {
+			le = formatFloat(s.Buckets[i])
+		}
+		bucketLabels[len(labels)].Value = le
+		m.Sample(name+"_bucket", float64(count), bucketLabels...)
+	}
+	m.Sample(name+"_sum", s.Sum, labels...)
+	m.Sample(name+"_count", float64(s.Count), labels...)
+}
+
+// Flush termine l'écriture.
+func (m *MetricsWriter) Flush() error {
+	return m.w.Flush()
+}
+
+func writeLabels(w *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	w.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(l.Name)
+		w.WriteString(`="`)
+		w.WriteString(labelEscaper.Replace(l.Value))
+		w.WriteByte('"')
+	}
+	w.WriteByte('}')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
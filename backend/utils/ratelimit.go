@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRateLimited est retournée (enveloppée dans *RateLimitError) quand un
+// appel ne peut pas partir avant l'échéance de son contexte.
+var ErrRateLimited = errors.New("outbound rate limit reached")
+
+// RateLimitError précise le fournisseur et l'attente nécessaire.
+type RateLimitError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: %v, retry in %s", e.Provider, ErrRateLimited, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *RateLimitError) Unwrap() error { return ErrRateLimited }
+
+// RateLimitPolicy configure un seau à jetons.
+type RateLimitPolicy struct {
+	RPS   float64 // jetons ajoutés par seconde (0 = illimité)
+	Burst int     // capacité du seau
+}
+
+// RateLimiter limite les appels sortants d'un fournisseur (seau à jetons).
+// Au-delà de la limite, un appel attend son jeton dans l'ordre d'arrivée ;
+// s'il ne peut pas l'obtenir avant l'échéance de son contexte, il échoue
+// immédiatement avec *RateLimitError.
+type RateLimiter struct {
+	name string
+	next http.RoundTripper
+	now  func() time.Time
+
+	mu     sync.Mutex
+	policy RateLimitPolicy
+	tokens float64 // négatif : jetons déjà promis aux appels en attente
+	last   time.Time
+
+	delayed  atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// NewRateLimiter enveloppe next (http.DefaultTransport si nil) ; name
+// identifie le fournisseur dans les journaux.
+func NewRateLimiter(name string, next http.RoundTripper, policy RateLimitPolicy) *RateLimiter {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	l := &RateLimiter{name: name, next: next, now: time.Now, policy: policy, tokens: float64(policy.Burst)}
+	l.last = l.now()
+	return l
+}
+
+// SetPolicy remplace la politique (rechargement de configuration).
+func (l *RateLimiter) SetPolicy(p RateLimitPolicy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.now())
+	l.policy = p
+	l.tokens = math.Min(l.tokens, float64(p.Burst))
+}
+
+func (l *RateLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	wait, err := l.reserve(req)
+	if err != nil {
+		return nil, err
+	}
+	if wait > 0 {
+		l.delayed.Add(1)
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			l.cancel()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	return l.next.RoundTrip(req)
+}
+
+// reserve prend un jeton et retourne l'attente avant de l'utiliser, ou une
+// erreur si cette attente dépasse l'échéance de la requête.
+func (l *RateLimiter) reserve(req *http.Request) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.policy.RPS <= 0 {
+		return 0, nil
+	}
+	now := l.now()
+	l.refill(now)
+
+	var wait time.Duration
+	if l.tokens < 1 {
+		wait = time.Duration((1 - l.tokens) / l.policy.RPS * float64(time.Second))
+	}
+	if deadline, ok := req.Context().Deadline(); ok && wait > 0 && now.Add(wait).After(deadline) {
+		l.rejected.Add(1)
+		LoggerFrom(req.Context()).WarnContext(req.Context(), "outbound rate limit reached", "provider", l.name, "retry_after", wait)
+		return 0, &RateLimitError{Provider: l.name, RetryAfter: wait}
+	}
+	l.tokens--
+	return wait, nil
+}
+
+// cancel rend le jeton d'un appel abandonné pendant son attente.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.tokens+1, float64(l.policy.Burst))
+}
+
+// refill ajoute les jetons accumulés depuis le dernier passage ; mu doit être tenu.
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.tokens+elapsed.Seconds()*l.policy.RPS, float64(l.policy.Burst))
+	}
+	l.last = now
+}
+
+// RateLimitStats résume l'activité d'un RateLimiter.
+type RateLimitStats struct {
+	RPS      float64 `json:"rps"`
+	Burst    int     `json:"burst"`
+	Delayed  uint64  `json:"delayed"`  // appels mis en attente d'un jeton
+	Rejected uint64  `json:"rejected"` // appels refusés (échéance trop proche)
+}
+
+// Stats retourne la politique et les compteurs du limiteur.
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.mu.Lock()
+	p := l.policy
+	l.mu.Unlock()
+	return RateLimitStats{RPS: p.RPS, Burst: p.Burst, Delayed: l.delayed.Load(), Rejected: l.rejected.Load()}
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted remplace les secrets dans les journaux, erreurs et réponses.
+const Redacted = "[REDACTED]"
+
+// minSecretLen évite de masquer des valeurs trop courtes pour être des secrets.
+const minSecretLen = 4
+
+var (
+	secretsMu sync.RWMutex
+	secrets   = make(map[string]struct{})
+	replacer  = strings.NewReplacer()
+)
+
+// sensitivePatterns masquent les secrets reconnaissables même s'ils n'ont
+// pas été enregistrés : paramètres d'URL (key=..., token=...), identifiants
+// dans une URL (user:pass@) et jetons Bearer.
+var sensitivePatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)([?&](?:key|api_?key|token|access_token|secret|password)=)[^&\s"']+`), "${1}" + Redacted},
+	{regexp.MustCompile(`(\w+://[^/\s:@]+:)[^/\s@]+@`), "${1}" + Redacted + "@"},
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`), "${1}" + Redacted},
+}
+
+// RegisterSecret ajoute une valeur à masquer partout (clés API, jetons).
+func RegisterSecret(secret string) {
+	if len(secret) < minSecretLen {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	if _, ok := secrets[secret]; ok {
+		return
+	}
+	secrets[secret] = struct{}{}
+
+	// les plus longs d'abord, pour qu'un secret préfixe d'un autre ne le coupe pas
+	all := make([]string, 0, len(secrets))
+	for s := range secrets {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool { return len(all[i]) > len(all[j]) })
+	pairs := make([]string, 0, 2*len(all))
+	for _, s := range all {
+		pairs = append(pairs, s, Redacted)
+	}
+	replacer = strings.NewReplacer(pairs...)
+}
+
+// Redact masque les secrets enregistrés et reconnaissables dans s.
+func Redact(s string) string {
+	secretsMu.RLock()
+	r := replacer
+	secretsMu.RUnlock()
+
+	s = r.Replace(s)
+	for _, p := range sensitivePatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// redactingWriter masque les secrets de tout ce qui est écrit.
+type redactingWriter struct {
+	w io.Writer
+}
+
+// NewRedactingWriter enveloppe w (ex. la sortie de log) avec Redact.
+func NewRedactingWriter(w io.Writer) io.Writer {
+	return redactingWriter{w: w}
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
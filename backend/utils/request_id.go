@@ -0,0 +1,16 @@
+package utils
+
+import "context"
+
+type requestIDKey struct{}
+
+// WithRequestID attache l'identifiant de requête à ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID retourne l'identifiant de requête porté par ctx ("" sinon).
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy configure les nouvelles tentatives d'un client HTTP.
+type RetryPolicy struct {
+	MaxRetries int           // tentatives supplémentaires (0 = aucune)
+	BaseDelay  time.Duration // délai avant la première nouvelle tentative
+	MaxDelay   time.Duration // plafond du backoff ; un Retry-After plus long arrête les tentatives
+}
+
+// RetryTransport rejoue les requêtes idempotentes (GET, HEAD) en cas
+// d'erreur réseau, de 429 ou de 5xx, avec un backoff exponentiel à jitter
+// complet. Retry-After est respecté, jamais raccourci : s'il dépasse
+// MaxDelay ou l'échéance du contexte, la réponse est retournée telle quelle.
+type RetryTransport struct {
+	name   string
+	base   http.RoundTripper
+	policy atomic.Pointer[RetryPolicy]
+
+	requests  atomic.Uint64
+	attempts  atomic.Uint64
+	retries   atomic.Uint64
+	exhausted atomic.Uint64
+}
+
+// NewRetryTransport enveloppe base (http.DefaultTransport si nil) ; name
+// identifie le fournisseur dans les journaux.
+func NewRetryTransport(name string, base http.RoundTripper, policy RetryPolicy) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &RetryTransport{name: name, base: base}
+	t.SetPolicy(policy)
+	return t
+}
+
+// SetPolicy remplace la politique (rechargement de configuration).
+func (t *RetryTransport) SetPolicy(p RetryPolicy) {
+	t.policy.Store(&p)
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	policy := *t.policy.Load()
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	for attempt := 0; ; attempt++ {
+		t.attempts.Add(1)
+		resp, err := t.base.RoundTrip(req)
+
+		if !idempotent || !retryable(req.Context(), resp, err) {
+			return resp, err
+		}
+		if attempt >= policy.MaxRetries {
+			if policy.MaxRetries > 0 {
+				t.exhausted.Add(1)
+				LoggerFrom(req.Context()).WarnContext(req.Context(), "upstream retries exhausted",
+					"provider", t.name, "retries", attempt, "reason", retryReason(resp, err))
+			}
+			return resp, err
+		}
+
+		delay := backoff(policy, attempt)
+		if ra, ok := retryAfter(resp); ok {
+			if ra > policy.MaxDelay {
+				// le fournisseur demande d'attendre plus que ce qu'on s'autorise
+				LoggerFrom(req.Context()).InfoContext(req.Context(), "upstream retry-after exceeds max delay, not retrying",
+					"provider", t.name, "retry_after", ra, "max_delay", policy.MaxDelay)
+				return resp, err
+			}
+			delay = ra
+		}
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) <= delay {
+			// pas le temps d'une nouvelle tentative avant l'échéance
+			return resp, err
+		}
+
+		LoggerFrom(req.Context()).InfoContext(req.Context(), "retrying upstream call",
+			"provider", t.name, "attempt", attempt+1, "max_retries", policy.MaxRetries, "delay", delay, "reason", retryReason(resp, err))
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		t.retries.Add(1)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable indique si la réponse (ou l'erreur) justifie une nouvelle tentative.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// une annulation, une échéance dépassée ou la limite de débit
+		// sortante ne sont pas des erreurs transitoires du fournisseur
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) &&
+			!errors.Is(err, ErrRateLimited)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "status " + strconv.Itoa(resp.StatusCode)
+}
+
+// backoff retourne un délai aléatoire dans [0, min(MaxDelay, BaseDelay*2^attempt)].
+func backoff(p RetryPolicy, attempt int) time.Duration {
+	ceiling := p.MaxDelay
+	if attempt < 30 {
+		if d := p.BaseDelay << attempt; d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// retryAfter lit l'en-tête Retry-After (secondes ou date HTTP).
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// RetryStats résume l'activité d'un RetryTransport.
+type RetryStats struct {
+	Requests  uint64 `json:"requests"`          // requêtes reçues
+	Attempts  uint64 `json:"attempts"`          // appels HTTP effectués (tentatives comprises)
+	Retries   uint64 `json:"retries"`           // nouvelles tentatives
+	Exhausted uint64 `json:"retries_exhausted"` // abandons après MaxRetries
+}
+
+// Stats retourne les compteurs du transport.
+func (t *RetryTransport) Stats() RetryStats {
+	return RetryStats{
+		Requests:  t.requests.Load(),
+		Attempts:  t.attempts.Load(),
+		Retries:   t.retries.Load(),
+		Exhausted: t.exhausted.Load(),
+	}
+}
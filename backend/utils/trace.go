@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName est le nom de la bibliothèque d'instrumentation des spans.
+const TracerName = "weather-app-backend"
+
+// Propagator lit et écrit le contexte de trace (W3C Trace Context :
+// en-tête traceparent) sur les requêtes reçues et envoyées.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// NewTracerProvider crée le fournisseur de traceurs : les spans sont
+// envoyés par lots à exporter ; ratio est la part des nouvelles traces
+// échantillonnées (une trace reçue par traceparent garde sa décision).
+// Les échecs d'envoi sont signalés à logger (qui peut être nil).
+func NewTracerProvider(exporter sdktrace.SpanExporter, serviceName string, ratio float64, logger *slog.Logger) *sdktrace.TracerProvider {
+	logger = OrDiscard(logger)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("exporting spans failed", "error", err)
+	}))
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+}
+
+// StartSpan démarre un span interne, enfant du span de ctx ; sans span
+// dans ctx (traçage désactivé), le span retourné n'enregistre rien.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracerFrom(ctx).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// tracerFrom retourne le traceur du fournisseur qui a créé le span de ctx.
+func tracerFrom(ctx context.Context) trace.Tracer {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(TracerName)
+}
+
+// SpanError marque span en échec ; le message est expurgé des secrets.
+// err nil : sans effet.
+func SpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	msg := Redact(err.Error())
+	span.RecordError(errors.New(msg))
+	span.SetStatus(codes.Error, msg)
+}
@@ -138,7 +138,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
 			try {
 				const res = await fetch(
-					`http://localhost:8080/api/weather?city=${encodeURIComponent(city)}`
+					`http://localhost:8080/api/v1/weather/${encodeURIComponent(city)}`
 				);
 
 				if (!res.ok) {